@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/rubblelabs/ripple/crypto"
 	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/keystore"
 	"github.com/rubblelabs/ripple/websockets"
 )
 
 type Action struct {
-	Seed         data.Seed
+	Seed data.Seed
+	// Label names a keystore entry to use instead of Seed. See Unlock.
+	Label        string `json:",omitempty"`
 	Fee          data.Value
 	KeyType      data.KeyType
 	AccountSets  []data.AccountSet
@@ -20,9 +24,50 @@ type Action struct {
 	Payments     []data.Payment
 }
 
+type actionJSON Action
+
+// The KeyType is detected from the Seed when it starts with sEd
+func (a *Action) UnmarshalJSON(b []byte) error {
+	extract := &struct {
+		*actionJSON
+		Seed *data.TypedSeed
+	}{
+		actionJSON: (*actionJSON)(a),
+	}
+	if err := json.Unmarshal(b, extract); err != nil {
+		return err
+	}
+	if extract.Seed == nil {
+		return nil
+	}
+	a.Seed = extract.Seed.Seed
+	if extract.Seed.KeyType == data.Ed25519 {
+		a.KeyType = data.Ed25519
+	}
+	return nil
+}
+
+// The Seed is left out when it comes from a keystore
+func (a Action) MarshalJSON() ([]byte, error) {
+	var seed *data.TypedSeed
+	if len(a.Label) == 0 {
+		seed = &data.TypedSeed{Seed: a.Seed, KeyType: a.KeyType}
+	}
+	return json.Marshal(struct {
+		actionJSON
+		Seed *data.TypedSeed `json:",omitempty"`
+	}{
+		actionJSON: actionJSON(a),
+		Seed:       seed,
+	})
+}
+
 type actionFunc func(seed data.Seed, fee data.Value, keyType data.KeyType, tx data.Transaction, txType data.TransactionType) error
 
 func (a *Action) each(f actionFunc) error {
+	if len(a.Label) > 0 && a.Seed == (data.Seed{}) {
+		return fmt.Errorf("Keystore entry is locked: %s", a.Label)
+	}
 	for i := range a.AccountSets {
 		if err := f(a.Seed, a.Fee, a.KeyType, &a.AccountSets[i], data.ACCOUNT_SET); err != nil {
 			return err
@@ -56,6 +101,24 @@ func Parse(r io.Reader) (ActionSlice, error) {
 	return actions, nil
 }
 
+// Unlock sets the Seed and KeyType of the actions which have a Label from
+// the keystore entries with that label. The same passphrase must open every
+// entry, set the Seed and KeyType from Keystore.Seed for entries with
+// different passphrases.
+func (s ActionSlice) Unlock(store *keystore.Keystore, passphrase []byte) error {
+	for i := range s {
+		if len(s[i].Label) == 0 {
+			continue
+		}
+		seed, keyType, err := store.Seed(s[i].Label, passphrase)
+		if err != nil {
+			return err
+		}
+		s[i].Seed, s[i].KeyType = *seed, keyType
+	}
+	return nil
+}
+
 func (s ActionSlice) each(f actionFunc) error {
 	for i := range s {
 		if err := s[i].each(f); err != nil {
@@ -68,14 +131,18 @@ func (s ActionSlice) each(f actionFunc) error {
 func (s ActionSlice) Prepare() error {
 	var prepare = func(seed data.Seed, fee data.Value, keyType data.KeyType, tx data.Transaction, txType data.TransactionType) error {
 		var (
-			sequence uint32
-			key      = seed.Key(keyType)
+			sequence *uint32
 			base     = tx.GetBase()
 		)
+		// Ed25519 keys have no account families
+		if keyType == data.ECDSA {
+			sequence = new(uint32)
+		}
 		base.TransactionType = txType
 		base.Fee = fee
-		base.Account = seed.AccountId(keyType, &sequence)
-		return data.Sign(tx, key, &sequence)
+		base.Account = seed.AccountId(keyType, sequence)
+		signer := crypto.NewKeySigner(seed.Key(keyType), sequence)
+		return data.Sign(tx, signer, signer.Public())
 	}
 	return s.each(prepare)
 }
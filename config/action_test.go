@@ -1,8 +1,13 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/keystore"
 )
 
 func TestParse(t *testing.T) {
@@ -20,3 +25,60 @@ func TestParse(t *testing.T) {
 	}
 	// t.Log(actions)
 }
+
+func TestKeyType(t *testing.T) {
+	actions, err := Parse(strings.NewReader(`[{"seed":"sEdVQ4wvD1AaTG6JA54qt38TengAuiz"},{"seed":"snoPBrXtMeMyMHUVTgbuqAfg1SUTb"}]`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if actions[0].KeyType != data.Ed25519 || actions[1].KeyType != data.ECDSA {
+		t.Fatalf("key types: %s %s", actions[0].KeyType, actions[1].KeyType)
+	}
+	if actions[0].Seed != actions[1].Seed {
+		t.Fatalf("seeds differ: %s %s", actions[0].Seed, actions[1].Seed)
+	}
+	if account := actions[0].Seed.AccountId(actions[0].KeyType, nil); account.String() != "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf" {
+		t.Fatalf("account: %s", account)
+	}
+	b, err := json.Marshal(actions[0])
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(b), `"Seed":"sEdVQ4wvD1AaTG6JA54qt38TengAuiz"`) {
+		t.Fatalf("marshal: %s", b)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	actions, err := Parse(strings.NewReader(`[{"label":"master","fee":"10","payments":[{"sequence":1,"destination":"rb1fWuuAEtPUaeEWxocV3h4x5JwDTFZzH","amount":"1"}]}]`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := actions.Prepare(); err == nil || err.Error() != "Keystore entry is locked: master" {
+		t.Fatalf("prepare: %v", err)
+	}
+	seed, _, err := data.NewSeedFromAddress("sEdVQ4wvD1AaTG6JA54qt38TengAuiz")
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	store := keystore.New()
+	if _, err := store.Add("master", *seed, data.Ed25519, []byte("secret")); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := actions.Unlock(store, []byte("secret")); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	if err := actions.Prepare(); err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	if account := actions[0].Payments[0].Account.String(); account != "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf" {
+		t.Fatalf("account: %s", account)
+	}
+	b, err := json.Marshal(actions[0])
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(b), `"Seed"`) {
+		t.Fatalf("marshal: %s", b)
+	}
+}
@@ -84,6 +84,9 @@ func Base58Decode(b, alphabet string) ([]byte, error) {
 	flen := numZeros + len(tmpval)
 	val := make([]byte, flen)
 	copy(val[numZeros:], tmpval)
+	if len(val) < 4 {
+		return nil, fmt.Errorf("Bad Base58 checksum: %s", b)
+	}
 
 	// Check checksum
 	checksum := DoubleSha256(val[0 : len(val)-4])
@@ -0,0 +1,216 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// A base58 codec for the short, fixed size values of hashTypes which works
+// on arrays of 32 bit limbs on the stack rather than with math/big. Five
+// base58 digits fit in each step as 58^5 < 2^32.
+
+const (
+	maxBase58Bytes = 40 // version, payload and checksum
+	maxBase58Chars = 55 // ceil(maxBase58Bytes * log(256) / log(58))
+	base58Limbs    = maxBase58Bytes / 4
+	base58Chunk    = 58 * 58 * 58 * 58 * 58
+	minBase58Chars = 5
+)
+
+// Built as a variable rather than in init so that it is ready for other
+// package level variables
+var base58Digits = func() (digits [256]int8) {
+	for i := range digits {
+		digits[i] = -1
+	}
+	for i := 0; i < len(ALPHABET); i++ {
+		digits[ALPHABET[i]] = int8(i)
+	}
+	return digits
+}()
+
+type Base58ErrorKind int
+
+const (
+	Base58BadCharacter Base58ErrorKind = iota + 1
+	Base58BadLength
+	Base58BadChecksum
+)
+
+// Base58Error reports why a string could not be decoded
+type Base58Error struct {
+	Kind     Base58ErrorKind
+	Input    string
+	Position int // of a bad character
+}
+
+func (e *Base58Error) Error() string {
+	switch {
+	case e.Kind == Base58BadCharacter:
+		return fmt.Sprintf("Bad Base58 character at %d: %s", e.Position, e.Input)
+	case e.Kind == Base58BadLength && len(e.Input) < minBase58Chars:
+		return fmt.Sprintf("Base58 string too short: %s", e.Input)
+	case e.Kind == Base58BadLength:
+		return fmt.Sprintf("Bad Base58 length: %s", e.Input)
+	default:
+		return fmt.Sprintf("Bad Base58 checksum: %s", e.Input)
+	}
+}
+
+func base58Checksum(b []byte) [4]byte {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	var checksum [4]byte
+	copy(checksum[:], second[:4])
+	return checksum
+}
+
+// encodeBase58 appends the base58 encoding of b, which must be no longer
+// than maxBase58Bytes, to dst
+func encodeBase58(dst, b []byte) []byte {
+	var limbs [base58Limbs]uint32
+	for i, c := range b {
+		pos := len(b) - 1 - i
+		limbs[base58Limbs-1-pos/4] |= uint32(c) << (8 * (pos % 4))
+	}
+	var out [maxBase58Chars + minBase58Chars]byte
+	j := len(out)
+	for start := base58Limbs - (len(b)+3)/4; start < base58Limbs; {
+		var rem uint64
+		for i := start; i < base58Limbs; i++ {
+			cur := rem<<32 | uint64(limbs[i])
+			limbs[i] = uint32(cur / base58Chunk)
+			rem = cur % base58Chunk
+		}
+		for start < base58Limbs && limbs[start] == 0 {
+			start++
+		}
+		for k := 0; k < 5; k++ {
+			j--
+			out[j] = ALPHABET[rem%58]
+			rem /= 58
+		}
+	}
+	// The last chunk may be padded with zeros
+	for j < len(out) && out[j] == ALPHABET[0] {
+		j++
+	}
+	for i := 0; i < len(b) && b[i] == 0; i++ {
+		j--
+		out[j] = ALPHABET[0]
+	}
+	return append(dst, out[j:]...)
+}
+
+// decodeBase58 decodes s into buf and returns the number of bytes
+func decodeBase58(buf *[maxBase58Bytes]byte, s string) (int, error) {
+	if len(s) < minBase58Chars || len(s) > maxBase58Chars {
+		return 0, &Base58Error{Kind: Base58BadLength, Input: s}
+	}
+	var limbs [base58Limbs]uint32
+	for i := 0; i < len(s); i += 5 {
+		value, mul := uint64(0), uint64(1)
+		for j := i; j < i+5 && j < len(s); j++ {
+			digit := base58Digits[s[j]]
+			if digit < 0 {
+				return 0, &Base58Error{Kind: Base58BadCharacter, Input: s, Position: j}
+			}
+			value = value*58 + uint64(digit)
+			mul *= 58
+		}
+		for k := base58Limbs - 1; k >= 0; k-- {
+			cur := uint64(limbs[k])*mul + value
+			limbs[k] = uint32(cur)
+			value = cur >> 32
+		}
+		if value != 0 {
+			return 0, &Base58Error{Kind: Base58BadLength, Input: s}
+		}
+	}
+	var raw [maxBase58Bytes]byte
+	for k, limb := range limbs {
+		raw[k*4] = byte(limb >> 24)
+		raw[k*4+1] = byte(limb >> 16)
+		raw[k*4+2] = byte(limb >> 8)
+		raw[k*4+3] = byte(limb)
+	}
+	first := 0
+	for first < len(raw) && raw[first] == 0 {
+		first++
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == ALPHABET[0] {
+		zeros++
+	}
+	n := zeros + len(raw) - first
+	if n > maxBase58Bytes {
+		return 0, &Base58Error{Kind: Base58BadLength, Input: s}
+	}
+	for i := 0; i < zeros; i++ {
+		buf[i] = 0
+	}
+	copy(buf[zeros:], raw[first:])
+	return n, nil
+}
+
+// AppendBase58Check appends to dst the encoding of b followed by its
+// checksum. It does not allocate when dst has room for the result.
+func AppendBase58Check(dst, b []byte) []byte {
+	if len(b)+4 > maxBase58Bytes {
+		return append(dst, Base58Encode(append([]byte(nil), b...), ALPHABET)...)
+	}
+	var buf [maxBase58Bytes]byte
+	n := copy(buf[:], b)
+	checksum := base58Checksum(b)
+	n += copy(buf[n:], checksum[:])
+	return encodeBase58(dst, buf[:n])
+}
+
+// DecodeBase58Check appends to dst the bytes encoded in s after checking
+// and removing the checksum. Errors are *Base58Error. It does not allocate
+// when dst has room for the result and s is valid.
+func DecodeBase58Check(dst []byte, s string) ([]byte, error) {
+	var buf [maxBase58Bytes]byte
+	n, err := decodeBase58(&buf, s)
+	if err != nil {
+		return nil, err
+	}
+	if n < 4 || base58Checksum(buf[:n-4]) != *(*[4]byte)(buf[n-4 : n]) {
+		return nil, &Base58Error{Kind: Base58BadChecksum, Input: s}
+	}
+	return append(dst, buf[:n-4]...), nil
+}
+
+// AppendRippleHash appends to dst the encoding of payload with version
+func AppendRippleHash(dst []byte, version HashVersion, payload []byte) ([]byte, error) {
+	if int(version) >= len(hashTypes) || hashTypes[version].Payload == 0 {
+		return nil, fmt.Errorf("Unknown hash version: %d", version)
+	}
+	if len(payload) != hashTypes[version].Payload {
+		return nil, fmt.Errorf("Bad payload length for %s: %d", hashTypes[version].Description, len(payload))
+	}
+	var buf [maxBase58Bytes]byte
+	buf[0] = byte(version)
+	n := 1 + copy(buf[1:], payload)
+	checksum := base58Checksum(buf[:n])
+	n += copy(buf[n:], checksum[:])
+	return encodeBase58(dst, buf[:n]), nil
+}
+
+// DecodeRippleHash appends to dst the payload encoded in s, which must have
+// version. Ed25519 family seeds, with their longer prefix, are not accepted.
+func DecodeRippleHash(dst []byte, s string, version HashVersion) ([]byte, error) {
+	var buf [maxBase58Bytes]byte
+	n, err := decodeBase58(&buf, s)
+	switch {
+	case err != nil:
+		return nil, err
+	case n < 4 || base58Checksum(buf[:n-4]) != *(*[4]byte)(buf[n-4 : n]):
+		return nil, &Base58Error{Kind: Base58BadChecksum, Input: s}
+	case HashVersion(buf[0]) != version:
+		return nil, fmt.Errorf("Bad version for: %s expected: %s", s, hashTypes[version].Description)
+	case n-5 != hashTypes[version].Payload:
+		return nil, &Base58Error{Kind: Base58BadLength, Input: s}
+	}
+	return append(dst, buf[1:n-4]...), nil
+}
@@ -0,0 +1,135 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+type Base58Suite struct{}
+
+var _ = Suite(&Base58Suite{})
+
+func (s *Base58Suite) TestRippleHash(c *C) {
+	for _, test := range []struct {
+		version  HashVersion
+		expected string
+	}{
+		{RIPPLE_ACCOUNT_ID, ROOT},
+		{RIPPLE_ACCOUNT_ID, ACCOUNT_ZERO},
+		{RIPPLE_ACCOUNT_ID, ACCOUNT_ONE},
+		{RIPPLE_FAMILY_SEED, "snoPBrXtMeMyMHUVTgbuqAfg1SUTb"},
+		{RIPPLE_NODE_PUBLIC, "n9KAa2zVWjPHgfzsE3iZ8HAbzJtPrnoh4H2M2HgE7dfqtvyEb1KJ"},
+		{RIPPLE_ACCOUNT_PUBLIC, "aBQG8RQAzjs1eTKFEAQXr2gS4utcDiEC9wmi7pfUPTi27VCahwgw"},
+	} {
+		payload, err := DecodeRippleHash(nil, test.expected, test.version)
+		c.Assert(err, IsNil)
+		c.Check(payload, HasLen, hashTypes[test.version].Payload)
+		encoded, err := AppendRippleHash(nil, test.version, payload)
+		c.Assert(err, IsNil)
+		c.Check(string(encoded), Equals, test.expected)
+		c.Check(string(encoded), Equals, Base58Encode(append([]byte{byte(test.version)}, payload...), ALPHABET))
+	}
+	_, err := AppendRippleHash(nil, RIPPLE_ACCOUNT_ID, make([]byte, 21))
+	c.Check(err, ErrorMatches, "Bad payload length for .*: 21")
+	_, err = DecodeRippleHash(nil, ROOT, RIPPLE_FAMILY_SEED)
+	c.Check(err, ErrorMatches, "Bad version for: .*")
+}
+
+func (s *Base58Suite) TestErrors(c *C) {
+	short, long := string(AppendBase58Check(nil, make([]byte, 17))), string(AppendBase58Check(nil, make([]byte, 22)))
+	for _, test := range []struct {
+		input    string
+		kind     Base58ErrorKind
+		position int
+		message  string
+	}{
+		{"Foo", Base58BadLength, 0, "Base58 string too short: Foo"},
+		{"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTl", Base58BadCharacter, 33, "Bad Base58 character at 33: .*"},
+		{"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTj", Base58BadChecksum, 0, "Bad Base58 checksum: .*"},
+		{"rrrrrrrrrrrrrrrrrrrrrhoLvT", Base58BadChecksum, 0, "Bad Base58 checksum: .*"},
+		{short, Base58BadLength, 0, "Bad Base58 length: .*"},
+		{long, Base58BadLength, 0, "Bad Base58 length: .*"},
+		{ROOT + ROOT, Base58BadLength, 0, "Bad Base58 length: .*"},
+	} {
+		_, err := DecodeRippleHash(nil, test.input, RIPPLE_ACCOUNT_ID)
+		c.Assert(err, FitsTypeOf, &Base58Error{})
+		c.Check(err.(*Base58Error).Kind, Equals, test.kind, Commentf(test.input))
+		c.Check(err.(*Base58Error).Position, Equals, test.position, Commentf(test.input))
+		c.Check(err, ErrorMatches, test.message)
+	}
+	_, err := NewRippleHashCheck(long, RIPPLE_ACCOUNT_ID)
+	c.Assert(err, FitsTypeOf, &Base58Error{})
+	c.Check(err.(*Base58Error).Kind, Equals, Base58BadLength)
+	_, err = NewRippleHashCheck("rHb9CJAWyB4rj91VRWn96DkukG4bwdtyT0", RIPPLE_ACCOUNT_ID)
+	c.Check(err.(*Base58Error).Kind, Equals, Base58BadCharacter)
+}
+
+func TestBase58Allocations(t *testing.T) {
+	payload, err := DecodeRippleHash(nil, ROOT, RIPPLE_ACCOUNT_ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 0, maxBase58Chars)
+	if n := testing.AllocsPerRun(100, func() {
+		buf, _ = AppendRippleHash(buf[:0], RIPPLE_ACCOUNT_ID, payload)
+		buf, _ = DecodeRippleHash(buf[:0], ROOT, RIPPLE_ACCOUNT_ID)
+		buf = AppendBase58Check(buf[:0], payload)
+	}); n != 0 {
+		t.Errorf("%.0f allocations", n)
+	}
+}
+
+func FuzzBase58(f *testing.F) {
+	for _, s := range []string{ROOT, ACCOUNT_ZERO, ACCOUNT_ONE, NaN, "sEdSKaCy2JT7JaM7v95H9SxkhP9wS2r"} {
+		b, _ := Base58Decode(s, ALPHABET)
+		f.Add(b[:len(b)-4], s)
+	}
+	f.Fuzz(func(t *testing.T, b []byte, s string) {
+		if len(b)+4 <= maxBase58Bytes {
+			if fast, slow := string(AppendBase58Check(nil, b)), Base58Encode(append([]byte(nil), b...), ALPHABET); fast != slow {
+				t.Fatalf("%X encoded as %s not %s", b, fast, slow)
+			}
+		}
+		fast, err := DecodeBase58Check(nil, s)
+		if err != nil {
+			return
+		}
+		slow, err := Base58Decode(s, ALPHABET)
+		if err != nil {
+			t.Fatalf("%s decoded as %X not %s", s, fast, err)
+		}
+		if !bytes.Equal(fast, slow[:len(slow)-4]) {
+			t.Fatalf("%s decoded as %X not %X", s, fast, slow[:len(slow)-4])
+		}
+	})
+}
+
+func BenchmarkBase58Encode(b *testing.B) {
+	payload, _ := DecodeBase58Check(nil, ROOT)
+	for i := 0; i < b.N; i++ {
+		Base58Encode(payload, ALPHABET)
+	}
+}
+
+func BenchmarkAppendRippleHash(b *testing.B) {
+	payload, _ := DecodeRippleHash(nil, ROOT, RIPPLE_ACCOUNT_ID)
+	buf := make([]byte, 0, maxBase58Chars)
+	for i := 0; i < b.N; i++ {
+		AppendRippleHash(buf[:0], RIPPLE_ACCOUNT_ID, payload)
+	}
+}
+
+func BenchmarkBase58Decode(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Base58Decode(ROOT, ALPHABET)
+	}
+}
+
+func BenchmarkDecodeRippleHash(b *testing.B) {
+	buf := make([]byte, 0, maxBase58Bytes)
+	for i := 0; i < b.N; i++ {
+		DecodeRippleHash(buf[:0], ROOT, RIPPLE_ACCOUNT_ID)
+	}
+}
@@ -0,0 +1,137 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// BIP32 derives a tree of secp256k1 keys from a seed. BIP44 fixes the path
+// for an account of a coin, 144 being that of XRP.
+
+// HardenedKeyStart is added to an index for hardened derivation
+const HardenedKeyStart = 0x80000000
+
+// BIP44Path is the path used by most wallets for the nth XRP account
+const BIP44Path = "m/44'/144'/0'/0/%d"
+
+type extendedKey struct {
+	key       *big.Int
+	chainCode []byte
+}
+
+func hmacSHA512(key, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
+
+func newMasterKey(seed []byte) (*extendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("Bad BIP32 seed length: %d", len(seed))
+	}
+	il, ir := hmacSHA512([]byte("Bitcoin seed"), seed)
+	key := new(big.Int).SetBytes(il)
+	if key.Cmp(zero) == 0 || key.Cmp(order) >= 0 {
+		return nil, fmt.Errorf("Unusable BIP32 seed")
+	}
+	return &extendedKey{key: key, chainCode: ir}, nil
+}
+
+func (k *extendedKey) private() *btcec.PrivateKey {
+	var b [32]byte
+	priv, _ := btcec.PrivKeyFromBytes(k.key.FillBytes(b[:]))
+	return priv
+}
+
+func (k *extendedKey) child(index uint32) (*extendedKey, error) {
+	data := make([]byte, 37)
+	if index >= HardenedKeyStart {
+		k.key.FillBytes(data[1:33])
+	} else {
+		copy(data, k.private().PubKey().SerializeCompressed())
+	}
+	binary.BigEndian.PutUint32(data[33:], index)
+	il, ir := hmacSHA512(k.chainCode, data)
+	key := new(big.Int).SetBytes(il)
+	if key.Cmp(order) >= 0 {
+		return nil, fmt.Errorf("Unusable BIP32 child: %d", index)
+	}
+	key.Add(key, k.key).Mod(key, order)
+	if key.Cmp(zero) == 0 {
+		return nil, fmt.Errorf("Unusable BIP32 child: %d", index)
+	}
+	return &extendedKey{key: key, chainCode: ir}, nil
+}
+
+// ParseBIP32Path returns the indices in a path such as m/44'/144'/0'/0/0.
+// Hardened indices are marked with ' or h.
+func ParseBIP32Path(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("Bad BIP32 path: %s", path)
+	}
+	indices := make([]uint32, len(parts)-1)
+	for i, part := range parts[1:] {
+		var hardened uint32
+		if trimmed := strings.TrimRight(part, "'h"); len(trimmed) == len(part)-1 {
+			part, hardened = trimmed, HardenedKeyStart
+		}
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || index >= HardenedKeyStart {
+			return nil, fmt.Errorf("Bad BIP32 path: %s", path)
+		}
+		indices[i] = uint32(index) + hardened
+	}
+	return indices, nil
+}
+
+// NewBIP32Key returns the key at path derived from seed, as returned by
+// MnemonicSeed. The key has no account family so sequences must be nil.
+func NewBIP32Key(seed []byte, path string) (Key, error) {
+	indices, err := ParseBIP32Path(path)
+	if err != nil {
+		return nil, err
+	}
+	k, err := newMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indices {
+		if k, err = k.child(index); err != nil {
+			return nil, err
+		}
+	}
+	return &bip32Key{ecdsaKey{k.private()}}, nil
+}
+
+// NewBIP44Key returns the key of the nth XRP account derived from seed
+func NewBIP44Key(seed []byte, n uint32) (Key, error) {
+	return NewBIP32Key(seed, fmt.Sprintf(BIP44Path, n))
+}
+
+type bip32Key struct {
+	ecdsaKey
+}
+
+func (k *bip32Key) Id(seq *uint32) []byte {
+	checkSequenceIsNil(seq)
+	return k.ecdsaKey.Id(nil)
+}
+
+func (k *bip32Key) Public(seq *uint32) []byte {
+	checkSequenceIsNil(seq)
+	return k.ecdsaKey.Public(nil)
+}
+
+func (k *bip32Key) Private(seq *uint32) []byte {
+	checkSequenceIsNil(seq)
+	return k.ecdsaKey.Private(nil)
+}
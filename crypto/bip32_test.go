@@ -0,0 +1,45 @@
+package crypto
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type BIP32Suite struct{}
+
+var _ = Suite(&BIP32Suite{})
+
+func (s *BIP32Suite) TestVectors(c *C) {
+	// Test vector 1 from BIP32
+	key, err := NewBIP32Key(h2b("000102030405060708090A0B0C0D0E0F"), "m/0'/1/2'/2/1000000000")
+	c.Assert(err, IsNil)
+	c.Check(b2h(key.Private(nil)), Equals, "471B76E389E528D6DE6D816857E012C5455051CAD6660850E58372A6C3E6E7C8")
+}
+
+func (s *BIP32Suite) TestBIP44(c *C) {
+	seed, err := MnemonicSeed(abandonMnemonic, "")
+	c.Assert(err, IsNil)
+	for n, test := range []struct {
+		public, account string
+	}{
+		{"031D68BC1A142E6766B2BDFB006CCFE135EF2E0E2E94ABB5CF5C9AB6104776FBAE", "rHsMGQEkVNJmpGWs8XUBoTBiAAbwxZN5v3"},
+		{"038BF420B5271ADA2D7479358FF98A29954CF18DC25155184AEAD05796DA737E89", "r3AgF9mMBFtaLhKcg96weMhbbEFLZ3mx17"},
+	} {
+		key, err := NewBIP44Key(seed, uint32(n))
+		c.Assert(err, IsNil)
+		c.Check(b2h(key.Public(nil)), Equals, test.public)
+		c.Check(checkHash(AccountId(key, nil)), Equals, test.account)
+		c.Check(checkSignature(c, key.Private(nil), key.Public(nil), Sha512Half([]byte("hash")), nil), Equals, true)
+	}
+}
+
+func (s *BIP32Suite) TestParsePath(c *C) {
+	indices, err := ParseBIP32Path("m/44'/144h/0'/0/7")
+	c.Assert(err, IsNil)
+	c.Check(indices, DeepEquals, []uint32{HardenedKeyStart + 44, HardenedKeyStart + 144, HardenedKeyStart, 0, 7})
+	for _, path := range []string{"44'/144'", "m/44''", "m/-1", "m/2147483648", "m//0"} {
+		_, err := ParseBIP32Path(path)
+		c.Check(err, ErrorMatches, "Bad BIP32 path: .*")
+	}
+	_, err = NewBIP32Key([]byte("short"), "m")
+	c.Check(err, ErrorMatches, "Bad BIP32 seed length: 5")
+}
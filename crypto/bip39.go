@@ -0,0 +1,123 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// BIP39 mnemonics encode 128 to 256 bits of entropy as 12 to 24 words from
+// a list of 2048, with a checksum taken from the SHA256 of the entropy.
+
+//go:embed bip39_english.txt
+var bip39English string
+
+var (
+	bip39Words   = strings.Fields(bip39English)
+	bip39Indices = make(map[string]int, len(bip39Words))
+)
+
+func init() {
+	for i, word := range bip39Words {
+		bip39Indices[word] = i
+	}
+}
+
+const bip39Rounds = 2048
+
+// NewMnemonic returns a random mnemonic encoding bits of entropy, which must
+// be a multiple of 32 from 128 to 256.
+func NewMnemonic(bits int) (string, error) {
+	if err := checkEntropyBits(bits); err != nil {
+		return "", err
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return EntropyToMnemonic(entropy)
+}
+
+func checkEntropyBits(bits int) error {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return fmt.Errorf("Bad mnemonic entropy length: %d bits", bits)
+	}
+	return nil
+}
+
+// EntropyToMnemonic returns the mnemonic which encodes entropy
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	if err := checkEntropyBits(len(entropy) * 8); err != nil {
+		return "", err
+	}
+	checksum := sha256.Sum256(entropy)
+	b := append(append([]byte(nil), entropy...), checksum[0])
+	words := make([]string, len(entropy)*3/4)
+	for i := range words {
+		words[i] = bip39Words[bits(b, i*11, 11)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy returns the entropy encoded in mnemonic after checking
+// the words and the checksum
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("Bad mnemonic length: %d words", len(words))
+	}
+	b := make([]byte, (len(words)*11+7)/8)
+	for i, word := range words {
+		index, ok := bip39Indices[word]
+		if !ok {
+			return nil, fmt.Errorf("Unknown mnemonic word: %s", word)
+		}
+		for j := 0; j < 11; j++ {
+			if index&(1<<(10-j)) != 0 {
+				pos := i*11 + j
+				b[pos/8] |= 0x80 >> (pos % 8)
+			}
+		}
+	}
+	entropy := b[:len(words)*4/3]
+	checksum := sha256.Sum256(entropy)
+	checksumBits := len(words) / 3
+	if bits(b, len(entropy)*8, checksumBits) != bits(checksum[:], 0, checksumBits) {
+		return nil, fmt.Errorf("Bad mnemonic checksum")
+	}
+	return entropy, nil
+}
+
+// CheckMnemonic returns an error if mnemonic is not valid
+func CheckMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// MnemonicSeed returns the 64 byte seed for mnemonic and an optional
+// passphrase, for use with NewBIP32Key. Wallets NFKD normalize the
+// passphrase first, which is left to the caller for non-ASCII passphrases.
+func MnemonicSeed(mnemonic, passphrase string) ([]byte, error) {
+	if err := CheckMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"+passphrase), bip39Rounds, sha512.Size, sha512.New), nil
+}
+
+// bits returns n bits of b from offset, most significant first
+func bits(b []byte, offset, n int) int {
+	var v int
+	for i := offset; i < offset+n; i++ {
+		v <<= 1
+		if b[i/8]&(0x80>>(i%8)) != 0 {
+			v |= 1
+		}
+	}
+	return v
+}
@@ -0,0 +1,58 @@
+package crypto
+
+import (
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type BIP39Suite struct{}
+
+var _ = Suite(&BIP39Suite{})
+
+const abandonMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// Vectors from https://github.com/trezor/python-mnemonic/blob/master/vectors.json
+var bip39Tests = []struct {
+	entropy, mnemonic, seed string
+}{
+	{"00000000000000000000000000000000", abandonMnemonic, "C55257C360C07C72029AEBC1B53C05ED0362ADA38EAD3E3E9EFA3708E53495531F09A6987599D18264C1E1C92F2CF141630C7A3C4AB7C81B2F001698E7463B04"},
+	{"7F7F7F7F7F7F7F7F7F7F7F7F7F7F7F7F", "legal winner thank year wave sausage worth useful legal winner thank yellow", ""},
+	{"80808080808080808080808080808080", "letter advice cage absurd amount doctor acoustic avoid letter advice cage above", ""},
+	{"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF", "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong", ""},
+	{"9E885D952AD362CAEB4EFE34A8E91BD2", "ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic", ""},
+}
+
+func (s *BIP39Suite) TestVectors(c *C) {
+	for _, test := range bip39Tests {
+		mnemonic, err := EntropyToMnemonic(h2b(test.entropy))
+		c.Assert(err, IsNil)
+		c.Check(mnemonic, Equals, test.mnemonic)
+		entropy, err := MnemonicToEntropy(test.mnemonic)
+		c.Assert(err, IsNil)
+		c.Check(b2h(entropy), Equals, test.entropy)
+		if test.seed != "" {
+			seed, err := MnemonicSeed(test.mnemonic, "TREZOR")
+			c.Assert(err, IsNil)
+			c.Check(b2h(seed), Equals, test.seed)
+		}
+	}
+}
+
+func (s *BIP39Suite) TestNewMnemonic(c *C) {
+	for _, bits := range []int{128, 160, 192, 224, 256} {
+		mnemonic, err := NewMnemonic(bits)
+		c.Assert(err, IsNil)
+		c.Check(strings.Fields(mnemonic), HasLen, bits*3/32)
+		c.Check(CheckMnemonic(mnemonic), IsNil)
+	}
+	_, err := NewMnemonic(100)
+	c.Check(err, ErrorMatches, "Bad mnemonic entropy length: 100 bits")
+}
+
+func (s *BIP39Suite) TestBadMnemonic(c *C) {
+	c.Check(CheckMnemonic("abandon abandon abandon"), ErrorMatches, "Bad mnemonic length: 3 words")
+	c.Check(CheckMnemonic(strings.Replace(abandonMnemonic, "about", "abandon", 1)), ErrorMatches, "Bad mnemonic checksum")
+	c.Check(CheckMnemonic(strings.Replace(abandonMnemonic, "about", "xrp", 1)), ErrorMatches, "Unknown mnemonic word: xrp")
+	c.Check(CheckMnemonic("  "+strings.Replace(abandonMnemonic, " ", "\n", 3)), IsNil)
+}
@@ -61,7 +61,8 @@ func (k *ecdsaKey) Id(sequence *uint32) []byte {
 
 func (k *ecdsaKey) Private(sequence *uint32) []byte {
 	if sequence == nil {
-		return k.ToECDSA().D.Bytes()
+		b := k.Key.Bytes()
+		return b[:]
 	}
 	b := k.generateKey(*sequence).Key.Bytes()
 	return b[:]
@@ -12,7 +12,7 @@ type ed25519key struct {
 
 func checkSequenceIsNil(seq *uint32) {
 	if seq != nil {
-		panic("Ed25519 keys do not support account families")
+		panic("Ed25519 and BIP32 keys do not support account families")
 	}
 }
 
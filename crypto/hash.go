@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 )
@@ -22,7 +23,8 @@ func NewRippleHash(s string) (Hash, error) {
 	}
 }
 
-// Checks hash matches expected version
+// Checks hash matches expected version and payload length. A bad
+// character, length or checksum is reported as a *Base58Error.
 func NewRippleHashCheck(s string, version HashVersion) (Hash, error) {
 	hash, err := NewRippleHash(s)
 	if err != nil {
@@ -30,9 +32,15 @@ func NewRippleHashCheck(s string, version HashVersion) (Hash, error) {
 	}
 	if hash.Version() != version {
 		want := hashTypes[version].Description
-		got := hashTypes[hash.Version()].Description
+		got := "Unknown."
+		if int(hash.Version()) < len(hashTypes) && hashTypes[hash.Version()].Payload > 0 {
+			got = hashTypes[hash.Version()].Description
+		}
 		return nil, fmt.Errorf("Bad version for: %s expected: %s got: %s ", s, want, got)
 	}
+	if len(hash.Payload()) != hashTypes[version].Payload {
+		return nil, &Base58Error{Kind: Base58BadLength, Input: s}
+	}
 	return hash, nil
 }
 
@@ -80,6 +88,22 @@ func NodePrivateKey(key Key) (Hash, error) {
 	return NewNodePrivateKey(key.Private(nil))
 }
 
+// NewEd25519FamilySeed returns a family seed for an ed25519 key, which is
+// encoded with a three byte prefix so that it starts with sEd
+func NewEd25519FamilySeed(b []byte) (Hash, error) {
+	if n := hashTypes[RIPPLE_FAMILY_SEED].Payload; len(b) != n {
+		return nil, fmt.Errorf("Hash is wrong size, expected: %d got: %d", n, len(b))
+	}
+	return newEd25519Seed(b), nil
+}
+
+// IsEd25519FamilySeed returns true if h is a family seed encoded for an
+// ed25519 key
+func IsEd25519FamilySeed(h Hash) bool {
+	_, ok := h.(ed25519Seed)
+	return ok
+}
+
 func GenerateFamilySeed(password string) (Hash, error) {
 	return NewFamilySeed(Sha512Quarter([]byte(password)))
 }
@@ -93,16 +117,22 @@ func newHash(b []byte, version HashVersion) (Hash, error) {
 }
 
 func newHashFromString(s string) (Hash, error) {
-	decoded, err := Base58Decode(s, ALPHABET)
-	if err != nil {
+	decoded, err := DecodeBase58Check(nil, s)
+	switch {
+	case err != nil:
 		return nil, err
+	case len(decoded) == 0:
+		return nil, &Base58Error{Kind: Base58BadLength, Input: s}
 	}
-	return hash(decoded[:len(decoded)-4]), nil
+	if isEd25519Seed(decoded) {
+		return newEd25519Seed(decoded[len(ed25519SeedPrefix):]), nil
+	}
+	return hash(decoded), nil
 }
 
 func (h hash) String() string {
-	b := append(hash{byte(h.Version())}, h.Payload()...)
-	return Base58Encode(b, ALPHABET)
+	var buf [maxBase58Chars]byte
+	return string(AppendBase58Check(buf[:0], h))
 }
 
 func (h hash) Version() HashVersion {
@@ -137,3 +167,35 @@ func (h hash) Clone() Hash {
 	copy(c, h)
 	return c
 }
+
+// ed25519Seed is a family seed which is encoded with ed25519SeedPrefix
+// in place of the version byte
+type ed25519Seed struct {
+	hash
+}
+
+var ed25519SeedPrefix = []byte{0x01, 0xE1, 0x4B}
+
+func isEd25519Seed(b []byte) bool {
+	return len(b) == len(ed25519SeedPrefix)+hashTypes[RIPPLE_FAMILY_SEED].Payload && bytes.HasPrefix(b, ed25519SeedPrefix)
+}
+
+func newEd25519Seed(b []byte) ed25519Seed {
+	return ed25519Seed{append(hash{byte(RIPPLE_FAMILY_SEED)}, b...)}
+}
+
+func (s ed25519Seed) String() string {
+	var b [maxBase58Bytes]byte
+	n := copy(b[:], ed25519SeedPrefix)
+	n += copy(b[n:], s.Payload())
+	var buf [maxBase58Chars]byte
+	return string(AppendBase58Check(buf[:0], b[:n]))
+}
+
+func (s ed25519Seed) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s ed25519Seed) Clone() Hash {
+	return ed25519Seed{s.hash.Clone().(hash)}
+}
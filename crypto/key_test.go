@@ -88,6 +88,15 @@ func (s *KeySuite) TestEd25119(c *C) {
 	seed, err := GenerateFamilySeed("masterpassphrase")
 	c.Check(err, IsNil)
 	c.Check(seed.String(), Equals, "snoPBrXtMeMyMHUVTgbuqAfg1SUTb")
+	edSeed, err := NewEd25519FamilySeed(seed.Payload())
+	c.Check(err, IsNil)
+	c.Check(edSeed.String(), Equals, "sEdVQ4wvD1AaTG6JA54qt38TengAuiz")
+	parsed, err := NewRippleHashCheck("sEdSKaCy2JT7JaM7v95H9SxkhP9wS2r", RIPPLE_FAMILY_SEED)
+	c.Check(err, IsNil)
+	c.Check(parsed.Payload(), DeepEquals, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	c.Check(parsed.String(), Equals, "sEdSKaCy2JT7JaM7v95H9SxkhP9wS2r")
+	c.Check(IsEd25519FamilySeed(parsed), Equals, true)
+	c.Check(IsEd25519FamilySeed(seed), Equals, false)
 	key, err := NewEd25519Key(seed.Payload())
 	c.Check(err, IsNil)
 	c.Check(checkHash(NodePublicKey(key)), Equals, "nHUeeJCSY2dM71oxM8Cgjouf5ekTuev2mwDpc374aLMxzDLXNmjf")
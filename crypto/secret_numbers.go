@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Secret numbers (XLS-12) write 16 bytes of seed as eight blocks of six
+// digits. Each block holds two bytes as five digits followed by a checksum
+// digit which depends on the position of the block.
+
+const (
+	secretNumberBlocks = 8
+	secretNumberDigits = 6
+)
+
+// SecretNumberError reports the block of secret numbers which is wrong
+type SecretNumberError struct {
+	Position int // from 1, or 0 if the number of blocks is wrong
+	Block    string
+	Reason   string
+}
+
+func (e *SecretNumberError) Error() string {
+	if e.Position == 0 {
+		return fmt.Sprintf("Bad secret numbers: %s", e.Reason)
+	}
+	return fmt.Sprintf("Bad secret number %d (%s): %s", e.Position, e.Block, e.Reason)
+}
+
+func secretNumberChecksum(position int, value uint16) int {
+	return int(value) * (position*2 + 1) % 9
+}
+
+// IsSecretNumbers returns true if s looks like secret numbers rather than a
+// base58 seed. It does not check the blocks.
+func IsSecretNumbers(s string) bool {
+	s = strings.TrimSpace(s)
+	return len(s) > 0 && s[0] >= '0' && s[0] <= '9'
+}
+
+// EncodeSecretNumbers returns the secret numbers for a 16 byte seed as
+// blocks separated by spaces
+func EncodeSecretNumbers(seed []byte) (string, error) {
+	if len(seed) != secretNumberBlocks*2 {
+		return "", fmt.Errorf("Bad seed length: %d", len(seed))
+	}
+	blocks := make([]string, secretNumberBlocks)
+	for i := range blocks {
+		value := binary.BigEndian.Uint16(seed[i*2:])
+		blocks[i] = fmt.Sprintf("%05d%d", value, secretNumberChecksum(i, value))
+	}
+	return strings.Join(blocks, " "), nil
+}
+
+// DecodeSecretNumbers returns the 16 byte seed written in s. The blocks may
+// be separated by whitespace or run together. Errors are
+// *SecretNumberError.
+func DecodeSecretNumbers(s string) ([]byte, error) {
+	blocks := strings.Fields(s)
+	if len(blocks) == 1 && len(blocks[0]) == secretNumberBlocks*secretNumberDigits {
+		joined := blocks[0]
+		blocks = make([]string, secretNumberBlocks)
+		for i := range blocks {
+			blocks[i] = joined[i*secretNumberDigits : (i+1)*secretNumberDigits]
+		}
+	}
+	if len(blocks) != secretNumberBlocks {
+		return nil, &SecretNumberError{Reason: fmt.Sprintf("%d blocks instead of %d", len(blocks), secretNumberBlocks)}
+	}
+	seed := make([]byte, secretNumberBlocks*2)
+	for i, block := range blocks {
+		if len(block) != secretNumberDigits {
+			return nil, &SecretNumberError{i + 1, block, fmt.Sprintf("%d digits instead of %d", len(block), secretNumberDigits)}
+		}
+		var value int
+		for _, r := range block[:secretNumberDigits-1] {
+			if r < '0' || r > '9' {
+				return nil, &SecretNumberError{i + 1, block, fmt.Sprintf("%q is not a digit", r)}
+			}
+			value = value*10 + int(r-'0')
+		}
+		if value > 0xFFFF {
+			return nil, &SecretNumberError{i + 1, block, "value too large"}
+		}
+		checksum := block[secretNumberDigits-1]
+		if checksum < '0' || checksum > '9' || int(checksum-'0') != secretNumberChecksum(i, uint16(value)) {
+			return nil, &SecretNumberError{i + 1, block, "bad checksum"}
+		}
+		binary.BigEndian.PutUint16(seed[i*2:], uint16(value))
+	}
+	return seed, nil
+}
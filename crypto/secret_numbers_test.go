@@ -0,0 +1,53 @@
+package crypto
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type SecretNumbersSuite struct{}
+
+var _ = Suite(&SecretNumbersSuite{})
+
+// Example from XLS-12
+const secretNumbersExample = "554872 394230 209376 323698 140250 387423 652803 258676"
+
+func (s *SecretNumbersSuite) TestRoundTrip(c *C) {
+	seed, err := DecodeSecretNumbers(secretNumbersExample)
+	c.Assert(err, IsNil)
+	c.Check(b2h(seed), Equals, "D8BF99FF51C97E7136C99756FF00650B")
+	numbers, err := EncodeSecretNumbers(seed)
+	c.Assert(err, IsNil)
+	c.Check(numbers, Equals, secretNumbersExample)
+	family, err := NewFamilySeed(seed)
+	c.Assert(err, IsNil)
+	c.Check(family.String(), Equals, "sn5ScMr4n1Kqc9DUJqVsGcSv7yb2U")
+
+	seed, err = DecodeSecretNumbers("554872394230209376323698140250387423652803258676")
+	c.Assert(err, IsNil)
+	c.Check(b2h(seed), Equals, "D8BF99FF51C97E7136C99756FF00650B")
+	c.Check(IsSecretNumbers(" 554872"), Equals, true)
+	c.Check(IsSecretNumbers("sn5ScMr4n1Kqc9DUJqVsGcSv7yb2U"), Equals, false)
+	_, err = EncodeSecretNumbers(seed[:15])
+	c.Check(err, ErrorMatches, "Bad seed length: 15")
+}
+
+func (s *SecretNumbersSuite) TestErrors(c *C) {
+	for _, test := range []struct {
+		numbers  string
+		position int
+		message  string
+	}{
+		{"554872 394230", 0, `Bad secret numbers: 2 blocks instead of 8`},
+		{"554872 394230 209375 323698 140250 387423 652803 258676", 3, `Bad secret number 3 \(209375\): bad checksum`},
+		{"554872 394230 209376 323698 140250 387423 652803 25867", 8, `Bad secret number 8 \(25867\): 5 digits instead of 6`},
+		{"554872 39423x 209376 323698 140250 387423 652803 258676", 2, `Bad secret number 2 \(39423x\): bad checksum`},
+		{"554872 3942a0 209376 323698 140250 387423 652803 258676", 2, `Bad secret number 2 \(3942a0\): 'a' is not a digit`},
+		{"554872 394230 209376 323698 140250 387423 652803 999990", 8, `Bad secret number 8 \(999990\): value too large`},
+	} {
+		_, err := DecodeSecretNumbers(test.numbers)
+		c.Check(err, ErrorMatches, test.message)
+		if e, ok := err.(*SecretNumberError); ok && test.position != 0 {
+			c.Check(e.Position, Equals, test.position)
+		}
+	}
+}
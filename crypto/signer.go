@@ -0,0 +1,61 @@
+package crypto
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Signer signs on behalf of the holder of publicKey without exposing the
+// private key, so that signing can be done by a local daemon, a hardware
+// module or a remote vault. payload is the signing hash for secp256k1 keys
+// and the whole message for ed25519 keys, as chosen by SigningPayload.
+type Signer interface {
+	Sign(publicKey, payload []byte) ([]byte, error)
+}
+
+// SignerFunc adapts a function to a Signer
+type SignerFunc func(publicKey, payload []byte) ([]byte, error)
+
+func (f SignerFunc) Sign(publicKey, payload []byte) ([]byte, error) {
+	return f(publicKey, payload)
+}
+
+// SigningPayload returns what a Signer for publicKey must sign: msg for
+// ed25519 keys and hash for secp256k1 keys.
+func SigningPayload(publicKey, hash, msg []byte) ([]byte, error) {
+	if len(publicKey) == 0 {
+		return nil, fmt.Errorf("Missing public key")
+	}
+	switch publicKey[0] {
+	case 0xED:
+		return msg, nil
+	case 0x02, 0x03:
+		return hash, nil
+	default:
+		return nil, fmt.Errorf("Unknown public key format")
+	}
+}
+
+// KeySigner is a Signer for a Key held in memory
+type KeySigner struct {
+	key      Key
+	sequence *uint32
+}
+
+// NewKeySigner returns a Signer for key. sequence selects the account in the
+// family for ECDSA keys and must be nil for ed25519 keys.
+func NewKeySigner(key Key, sequence *uint32) *KeySigner {
+	return &KeySigner{key: key, sequence: sequence}
+}
+
+// Public returns the public key of the signer
+func (k *KeySigner) Public() []byte {
+	return k.key.Public(k.sequence)
+}
+
+func (k *KeySigner) Sign(publicKey, payload []byte) ([]byte, error) {
+	if !bytes.Equal(publicKey, k.Public()) {
+		return nil, fmt.Errorf("Unknown public key: %X", publicKey)
+	}
+	return Sign(k.key.Private(k.sequence), payload, payload)
+}
@@ -0,0 +1,47 @@
+package crypto
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type SignerSuite struct{}
+
+var _ = Suite(&SignerSuite{})
+
+func (s *SignerSuite) TestKeySigner(c *C) {
+	hash, msg := Sha512Half([]byte("message")), []byte("message")
+	ecdsaKey, err := NewECDSAKey(h2b("71ED064155FFADFA38782C5E0158CB26"))
+	c.Assert(err, IsNil)
+	ed25519Key, err := NewEd25519Key(h2b("71ED064155FFADFA38782C5E0158CB26"))
+	c.Assert(err, IsNil)
+	var sequence uint32
+	for _, signer := range []*KeySigner{
+		NewKeySigner(ecdsaKey, &sequence),
+		NewKeySigner(ecdsaKey, nil),
+		NewKeySigner(ed25519Key, nil),
+	} {
+		payload, err := SigningPayload(signer.Public(), hash, msg)
+		c.Assert(err, IsNil)
+		sig, err := signer.Sign(signer.Public(), payload)
+		c.Assert(err, IsNil)
+		ok, err := Verify(signer.Public(), hash, msg, sig)
+		c.Check(err, IsNil)
+		c.Check(ok, Equals, true)
+	}
+	_, err = NewKeySigner(ecdsaKey, &sequence).Sign(ed25519Key.Public(nil), msg)
+	c.Check(err, ErrorMatches, "Unknown public key: .*")
+}
+
+func (s *SignerSuite) TestSigningPayload(c *C) {
+	hash, msg := []byte("hash"), []byte("msg")
+	payload, err := SigningPayload([]byte{0xED, 0x01}, hash, msg)
+	c.Check(err, IsNil)
+	c.Check(string(payload), Equals, "msg")
+	payload, err = SigningPayload([]byte{0x03, 0x01}, hash, msg)
+	c.Check(err, IsNil)
+	c.Check(string(payload), Equals, "hash")
+	_, err = SigningPayload(nil, hash, msg)
+	c.Check(err, ErrorMatches, "Missing public key")
+	_, err = SigningPayload([]byte{0x04}, hash, msg)
+	c.Check(err, ErrorMatches, "Unknown public key format")
+}
@@ -0,0 +1,82 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// X-addresses (XLS-5d) pack an account id with an optional destination tag:
+// two bytes of network prefix, the 20 byte account id, a flag byte and a
+// 64 bit little endian tag of which only the lower 32 bits are used.
+
+var (
+	xAddressMainnet = []byte{0x05, 0x44}
+	xAddressTestnet = []byte{0x04, 0x93}
+)
+
+const (
+	xAddressLength = 31
+	xAddressNoTag  = 0
+	xAddressTag    = 1
+)
+
+// IsXAddress returns true if s looks like an X-address rather than a
+// classic address. It does not check the encoding.
+func IsXAddress(s string) bool {
+	return len(s) > 0 && (s[0] == 'X' || s[0] == 'T')
+}
+
+// EncodeXAddress returns the X-address of account with an optional tag for
+// mainnet or testnet.
+func EncodeXAddress(account []byte, tag *uint32, testnet bool) (string, error) {
+	if len(account) != hashTypes[RIPPLE_ACCOUNT_ID].Payload {
+		return "", fmt.Errorf("Bad account id length: %d", len(account))
+	}
+	b := make([]byte, 0, xAddressLength)
+	if testnet {
+		b = append(b, xAddressTestnet...)
+	} else {
+		b = append(b, xAddressMainnet...)
+	}
+	b = append(b, account...)
+	var flag byte = xAddressNoTag
+	var value [8]byte
+	if tag != nil {
+		flag = xAddressTag
+		binary.LittleEndian.PutUint32(value[:], *tag)
+	}
+	b = append(b, flag)
+	b = append(b, value[:]...)
+	return string(AppendBase58Check(nil, b)), nil
+}
+
+// DecodeXAddress returns the account id and tag packed into the X-address s
+// and whether it is for testnet.
+func DecodeXAddress(s string) (account []byte, tag *uint32, testnet bool, err error) {
+	b, err := DecodeBase58Check(nil, s)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if len(b) != xAddressLength {
+		return nil, nil, false, fmt.Errorf("Bad X-address length: %s", s)
+	}
+	switch {
+	case bytes.Equal(b[:2], xAddressMainnet):
+	case bytes.Equal(b[:2], xAddressTestnet):
+		testnet = true
+	default:
+		return nil, nil, false, fmt.Errorf("Bad X-address prefix: %s", s)
+	}
+	account = b[2:22]
+	value := binary.LittleEndian.Uint64(b[23:31])
+	switch {
+	case b[22] == xAddressNoTag && value == 0:
+	case b[22] == xAddressTag && value <= 0xFFFFFFFF:
+		t := uint32(value)
+		tag = &t
+	default:
+		return nil, nil, false, fmt.Errorf("Bad X-address tag: %s", s)
+	}
+	return account, tag, testnet, nil
+}
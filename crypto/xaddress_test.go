@@ -0,0 +1,40 @@
+package crypto
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type XAddressSuite struct{}
+
+var _ = Suite(&XAddressSuite{})
+
+func (s *XAddressSuite) TestXAddress(c *C) {
+	account := accountCheck("rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf").Payload()
+	one, max := uint32(1), uint32(4294967295)
+	for _, test := range []struct {
+		tag      *uint32
+		testnet  bool
+		expected string
+	}{
+		{nil, false, "XVLhHMPHU98es4dbozjVtdWzVrDjtV5fdx1mHp98tDMoQXb"},
+		{&one, false, "XVLhHMPHU98es4dbozjVtdWzVrDjtV8xvjGQTYPiAx6gwDC"},
+		{&max, false, "XVLhHMPHU98es4dbozjVtdWzVrDjtV18pX8yuPT7y4xaEHi"},
+		{nil, true, "TVE26TYGhfLC7tQDno7G8dGtxSkYQn49b3qD26PK7FcGSKE"},
+		{&one, true, "TVE26TYGhfLC7tQDno7G8dGtxSkYQnSz1uDimDdPYXzSpyw"},
+	} {
+		address, err := EncodeXAddress(account, test.tag, test.testnet)
+		c.Assert(err, IsNil)
+		c.Check(address, Equals, test.expected)
+		c.Check(IsXAddress(address), Equals, true)
+		decoded, tag, testnet, err := DecodeXAddress(address)
+		c.Assert(err, IsNil)
+		c.Check(decoded, DeepEquals, account)
+		c.Check(tag, DeepEquals, test.tag)
+		c.Check(testnet, Equals, test.testnet)
+	}
+	_, _, _, err := DecodeXAddress("XVLhHMPHU98es4dbozjVtdWzVrDjtV5fdx1mHp98tDMoQXc")
+	c.Check(err, ErrorMatches, "Bad Base58 checksum:.*")
+	_, _, _, err = DecodeXAddress(ROOT)
+	c.Check(err, ErrorMatches, "Bad X-address length:.*")
+	c.Check(IsXAddress(ROOT), Equals, false)
+}
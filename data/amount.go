@@ -61,6 +61,11 @@ func NewAmount(v interface{}) (*Amount, error) {
 				copy(amount.Issuer[:], issuer.Payload())
 			}
 		}
+		if len(parts) > 2 || (len(parts) == 2 && !native) {
+			if err := amount.Currency.CheckIssued(); err != nil {
+				return nil, err
+			}
+		}
 		return amount, nil
 	default:
 		return nil, fmt.Errorf("Bad type: %+v", v)
@@ -234,27 +239,52 @@ func (a Amount) Asset() *Asset {
 		}
 	default:
 		return &Asset{
-			Currency: a.Currency.String(),
+			Currency: a.Currency.Machine(),
 			Issuer:   a.Issuer.String(),
 		}
 	}
 }
 
+func (a Amount) Issue() Issue {
+	return Issue{
+		Currency: a.Currency,
+		Issuer:   a.Issuer,
+	}
+}
+
+// NewExchangeRate returns the quality of an offer which pays a for b, as it
+// is stored in an order book directory. XRP are interpreted as drops.
 func NewExchangeRate(a, b *Amount) (ExchangeRate, error) {
 	if b.IsZero() {
 		return 0, nil
 	}
-	rate, err := a.Divide(b)
+	num, err := a.Value.NonNative()
+	if err != nil {
+		return 0, err
+	}
+	den, err := b.Value.NonNative()
+	if err != nil {
+		return 0, err
+	}
+	rate, err := num.Abs().Divide(*den.Abs())
 	if err != nil {
 		return 0, err
 	}
 	if rate.IsZero() {
 		return 0, nil
 	}
-	if rate.offset >= -100 || rate.offset <= 155 {
-		panic("Impossible Rate")
+	if rate.offset < -100 || rate.offset > 155 {
+		return 0, fmt.Errorf("Impossible rate: %s", rate.debug())
+	}
+	return ExchangeRate(uint64(rate.offset+100)<<56 | rate.num), nil
+}
+
+// Value returns the rate as a non-native Value
+func (e ExchangeRate) Value() *Value {
+	if e == 0 {
+		return zeroNonNative.Clone()
 	}
-	return ExchangeRate(uint64(rate.offset+100)<<54 | uint64(rate.num)), nil
+	return newValue(false, false, uint64(e)&(1<<56-1), int64(e>>56)-100)
 }
 
 func (e *ExchangeRate) Bytes() []byte {
@@ -0,0 +1,300 @@
+package data
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Arithmetic reproduces the rounding of rippled's STAmount operations
+// exactly, so that the results of offer crossing and payments can be
+// predicted to the last digit. The zero value matches ledgers before the
+// fixUniversalNumber amendment.
+//
+// Unlike the methods of Value, the native argument of each operation
+// selects whether the result is XRP, as the Issue argument does in rippled.
+type Arithmetic struct {
+	// UniversalNumber selects the Number based arithmetic of fixUniversalNumber
+	UniversalNumber bool
+}
+
+// Add returns a+b
+func (ar Arithmetic) Add(a, b Value) (*Value, error) {
+	switch {
+	case a.IsNative() != b.IsNative():
+		return nil, fmt.Errorf("Cannot add native and non-native values")
+	case b.IsZero():
+		return a.Clone(), nil
+	case a.IsZero():
+		return b.Clone(), nil
+	case a.IsNative():
+		sum := signed(a) + signed(b)
+		v := newValue(true, sum < 0, abs(sum), 0)
+		return v, v.canonicalise()
+	case ar.UniversalNumber:
+		x, y := Number{int64(signed(a)), int(a.offset)}, Number{int64(signed(b)), int(b.offset)}
+		sum, err := x.Add(y, RoundToNearest)
+		if err != nil {
+			return nil, err
+		}
+		return sum.Value(false, RoundToNearest)
+	}
+	av, bv, ao := signed(a), signed(b), a.offset
+	for bo := b.offset; ao < bo; ao++ {
+		av /= 10
+	}
+	for bo := b.offset; bo < ao; bo++ {
+		bv /= 10
+	}
+	sum := av + bv
+	if sum >= -10 && sum <= 10 {
+		return zeroNonNative.Clone(), nil
+	}
+	v := newValue(false, sum < 0, abs(sum), ao)
+	return v, v.canonicalise()
+}
+
+// Subtract returns a-b
+func (ar Arithmetic) Subtract(a, b Value) (*Value, error) {
+	return ar.Add(a, *b.Negate())
+}
+
+// Multiply returns a*b without directed rounding, as rippled's multiply
+func (ar Arithmetic) Multiply(a, b Value, native bool) (*Value, error) {
+	switch {
+	case a.IsZero() || b.IsZero():
+		return zeroValue(native), nil
+	case a.IsNative() && b.IsNative() && native:
+		return nativeMultiply(a, b)
+	case ar.UniversalNumber:
+		return ar.numberOperation(a, b, native, Number.Mul)
+	}
+	av, bv, ao, bo := normalise(a, b)
+	m, err := mulDiv(av, bv, tenTo14, 0)
+	if err != nil {
+		return nil, err
+	}
+	return ar.construct(native, a.negative != b.negative, m+7, ao+bo+14, RoundToNearest)
+}
+
+// Divide returns num/den without directed rounding, as rippled's divide
+func (ar Arithmetic) Divide(num, den Value, native bool) (*Value, error) {
+	switch {
+	case den.IsZero():
+		return nil, fmt.Errorf("Division by zero")
+	case num.IsZero():
+		return zeroValue(native), nil
+	case ar.UniversalNumber:
+		return ar.numberOperation(num, den, native, Number.Div)
+	}
+	nv, dv, no, do := normalise(num, den)
+	m, err := mulDiv(nv, tenTo17, dv, 0)
+	if err != nil {
+		return nil, err
+	}
+	return ar.construct(native, num.negative != den.negative, m+5, no-do-17, RoundToNearest)
+}
+
+// MulRound returns a*b rounded towards positive infinity if roundUp is set
+// or towards negative infinity otherwise, as rippled's mulRound.
+func (ar Arithmetic) MulRound(a, b Value, native, roundUp bool) (*Value, error) {
+	return ar.mulRound(a, b, native, roundUp, false)
+}
+
+// MulRoundStrict is MulRound with the corrected rounding of XRP results
+// introduced by fixReducedOffersV1, as rippled's mulRoundStrict.
+func (ar Arithmetic) MulRoundStrict(a, b Value, native, roundUp bool) (*Value, error) {
+	return ar.mulRound(a, b, native, roundUp, true)
+}
+
+// DivRound returns num/den rounded towards positive infinity if roundUp is
+// set or towards negative infinity otherwise, as rippled's divRound.
+func (ar Arithmetic) DivRound(num, den Value, native, roundUp bool) (*Value, error) {
+	return ar.divRound(num, den, native, roundUp, false)
+}
+
+// DivRoundStrict is DivRound with the corrected rounding of XRP results
+// introduced by fixReducedOffersV1, as rippled's divRoundStrict.
+func (ar Arithmetic) DivRoundStrict(num, den Value, native, roundUp bool) (*Value, error) {
+	return ar.divRound(num, den, native, roundUp, true)
+}
+
+func (ar Arithmetic) mulRound(a, b Value, native, roundUp, strict bool) (*Value, error) {
+	switch {
+	case a.IsZero() || b.IsZero():
+		return zeroValue(native), nil
+	case a.IsNative() && b.IsNative() && native:
+		return nativeMultiply(a, b)
+	}
+	av, bv, ao, bo := normalise(a, b)
+	negative := a.negative != b.negative
+	// Round away from zero by adding the rounding before dividing
+	var rounding uint64
+	if negative != roundUp {
+		rounding = tenTo14m1
+	}
+	m, err := mulDiv(av, bv, tenTo14, rounding)
+	if err != nil {
+		return nil, err
+	}
+	return ar.round(native, negative, roundUp, strict, m, ao+bo+14)
+}
+
+func (ar Arithmetic) divRound(num, den Value, native, roundUp, strict bool) (*Value, error) {
+	switch {
+	case den.IsZero():
+		return nil, fmt.Errorf("Division by zero")
+	case num.IsZero():
+		return zeroValue(native), nil
+	}
+	nv, dv, no, do := normalise(num, den)
+	negative := num.negative != den.negative
+	var rounding uint64
+	if negative != roundUp {
+		rounding = dv - 1
+	}
+	m, err := mulDiv(nv, tenTo17, dv, rounding)
+	if err != nil {
+		return nil, err
+	}
+	return ar.round(native, negative, roundUp, strict, m, no-do-17)
+}
+
+// round finishes mulRound and divRound once the mantissas are combined
+func (ar Arithmetic) round(native, negative, roundUp, strict bool, m uint64, offset int64) (*Value, error) {
+	if negative != roundUp {
+		m, offset = canonicaliseRound(native, m, offset, roundUp, strict)
+	}
+	// The strict versions make Number truncate the remaining digits
+	mode := RoundToNearest
+	if strict {
+		mode = RoundTowardsZero
+	}
+	v, err := ar.construct(native, negative, m, offset, mode)
+	if err != nil {
+		return nil, err
+	}
+	if roundUp && !negative && v.IsZero() {
+		// The smallest value above zero
+		if native {
+			return newValue(true, false, 1, 0), nil
+		}
+		return newValue(false, false, minValue, minOffset), nil
+	}
+	return v, nil
+}
+
+// canonicaliseRound reduces m to the precision of the result, rounding up
+// the last digit, as rippled's canonicalizeRound and canonicalizeRoundStrict.
+func canonicaliseRound(native bool, m uint64, offset int64, roundUp, strict bool) (uint64, int64) {
+	switch {
+	case native && offset < 0:
+		loops, remainder := 0, false
+		for ; offset < -1; offset++ {
+			remainder = remainder || m%10 != 0
+			m /= 10
+			loops++
+		}
+		switch {
+		case strict && remainder && roundUp:
+			m += 10
+		case strict:
+			m += 9
+		case loops >= 2:
+			// The original version always rounds up when only one digit is removed
+			m += 9
+		default:
+			m += 10
+		}
+		return m / 10, offset + 1
+	case !native && m > maxValue:
+		for ; m > 10*maxValue; offset++ {
+			m /= 10
+		}
+		return (m + 9) / 10, offset + 1
+	default:
+		return m, offset
+	}
+}
+
+// construct canonicalises a result as rippled's STAmount constructor. Once
+// fixUniversalNumber is enabled the remaining digits are rounded with mode,
+// before then they are truncated.
+func (ar Arithmetic) construct(native, negative bool, m uint64, offset int64, mode RoundingMode) (*Value, error) {
+	if ar.UniversalNumber {
+		return numberValue(native, negative, m, int(offset), mode)
+	}
+	if native {
+		if m == 0 || offset <= -20 {
+			return zeroNative.Clone(), nil
+		}
+		for ; offset < 0; offset++ {
+			m /= 10
+		}
+		for ; offset > 0; offset-- {
+			// Check before multiplying so that m cannot wrap
+			if m > maxNative/10 {
+				return nil, fmt.Errorf("Native amount out of range: %de%d", m, offset)
+			}
+			m *= 10
+		}
+	}
+	v := newValue(native, negative, m, offset)
+	if err := v.canonicalise(); err != nil {
+		return nil, err
+	}
+	if v.IsZero() {
+		return zeroValue(native), nil
+	}
+	return v, nil
+}
+
+func (ar Arithmetic) numberOperation(a, b Value, native bool, op func(Number, Number, RoundingMode) (Number, error)) (*Value, error) {
+	x, err := a.Number(RoundToNearest)
+	if err != nil {
+		return nil, err
+	}
+	y, err := b.Number(RoundToNearest)
+	if err != nil {
+		return nil, err
+	}
+	z, err := op(x, y, RoundToNearest)
+	if err != nil {
+		return nil, err
+	}
+	return z.Value(native, RoundToNearest)
+}
+
+func nativeMultiply(a, b Value) (*Value, error) {
+	min, max := min64(a.num, b.num), max64(a.num, b.num)
+	if min > maxNativeSqrt || (((max >> 32) * min) > maxNativeDiv) {
+		return nil, fmt.Errorf("Native value overflow: %s*%s", a.debug(), b.debug())
+	}
+	v := newValue(true, a.negative != b.negative, min*max, 0)
+	return v, v.canonicalise()
+}
+
+// mulDiv returns (a*b+rounding)/c
+func mulDiv(a, b, c, rounding uint64) (uint64, error) {
+	hi, lo := bits.Mul64(a, b)
+	lo, carry := bits.Add64(lo, rounding, 0)
+	hi += carry
+	if hi >= c {
+		return 0, fmt.Errorf("mulDiv overflow: %d*%d/%d", a, b, c)
+	}
+	q, _ := bits.Div64(hi, lo, c)
+	return q, nil
+}
+
+func zeroValue(native bool) *Value {
+	if native {
+		return zeroNative.Clone()
+	}
+	return zeroNonNative.Clone()
+}
+
+func signed(v Value) int64 {
+	if v.negative {
+		return -int64(v.num)
+	}
+	return int64(v.num)
+}
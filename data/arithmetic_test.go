@@ -0,0 +1,240 @@
+package data
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type ArithmeticSuite struct{}
+
+var _ = Suite(&ArithmeticSuite{})
+
+func arithmeticResult(v *Value, err error) string {
+	switch {
+	case err != nil:
+		return "error"
+	case v.IsZero():
+		return "0"
+	case v.negative && v.native:
+		return fmt.Sprintf("-%d", v.num)
+	case v.native:
+		return fmt.Sprintf("%d", v.num)
+	case v.negative:
+		return fmt.Sprintf("-%de%d", v.num, v.offset)
+	default:
+		return fmt.Sprintf("%de%d", v.num, v.offset)
+	}
+}
+
+// The corpus in testdata/arithmetic.txt is generated by
+// testdata/arithmetic.py, a port of rippled's STAmount and Number
+// arithmetic to Python's exact integers. It follows the same algorithms so
+// it guards against regressions rather than checking them independently;
+// the TestRippled tests cover rippled's own expectations.
+func (s *ArithmeticSuite) TestCorpus(c *C) {
+	f, err := os.Open("testdata/arithmetic.txt")
+	c.Assert(err, IsNil)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	var count int
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		c.Assert(fields, HasLen, 7)
+		ar := Arithmetic{UniversalNumber: fields[1] == "true"}
+		native, roundUp := fields[2] == "true", fields[3] == "true"
+		a, b := amountCheck(fields[4]).Value, amountCheck(fields[5]).Value
+		var result *Value
+		switch fields[0] {
+		case "add":
+			result, err = ar.Add(*a, *b)
+		case "mul":
+			result, err = ar.Multiply(*a, *b, native)
+		case "div":
+			result, err = ar.Divide(*a, *b, native)
+		case "mulRound":
+			result, err = ar.MulRound(*a, *b, native, roundUp)
+		case "divRound":
+			result, err = ar.DivRound(*a, *b, native, roundUp)
+		case "mulRoundStrict":
+			result, err = ar.MulRoundStrict(*a, *b, native, roundUp)
+		case "divRoundStrict":
+			result, err = ar.DivRoundStrict(*a, *b, native, roundUp)
+		default:
+			c.Fatalf("Unknown operation: %s", fields[0])
+		}
+		c.Check(arithmeticResult(result, err), Equals, fields[6], Commentf("%s", line))
+		count++
+	}
+	c.Assert(scanner.Err(), IsNil)
+	c.Check(count > 4000, Equals, true)
+}
+
+func number(c *C, mantissa int64, exponent int) Number {
+	n, err := NewNumber(mantissa, exponent, RoundToNearest)
+	c.Assert(err, IsNil)
+	return n
+}
+
+// Cases from rippled's Number_test.cpp and STAmount_test.cpp
+func (s *ArithmeticSuite) TestRippled(c *C) {
+	for _, test := range []struct {
+		op             string
+		a, b, expected Number
+	}{
+		{"add", number(c, 1000000000000000, -15), number(c, 6555555555555555, -29), number(c, 1000000000000066, -15)},
+		{"add", number(c, -1000000000000000, -15), number(c, -6555555555555555, -29), number(c, -1000000000000066, -15)},
+		{"add", number(c, -1000000000000000, -15), number(c, 6555555555555555, -29), number(c, -9999999999999344, -16)},
+		{"add", number(c, -6555555555555555, -29), number(c, 1000000000000000, -15), number(c, 9999999999999344, -16)},
+		{"add", number(c, 0, 0), number(c, 5, 0), number(c, 5, 0)},
+		{"mul", number(c, 7, 0), number(c, 8, 0), number(c, 56, 0)},
+		{"mul", number(c, 1414213562373095, -15), number(c, 1414213562373095, -15), number(c, 2000000000000000, -15)},
+		{"mul", number(c, -1414213562373095, -15), number(c, 1414213562373095, -15), number(c, -2000000000000000, -15)},
+		{"mul", number(c, -1414213562373095, -15), number(c, -1414213562373095, -15), number(c, 2000000000000000, -15)},
+		{"mul", number(c, 3214285714285706, -15), number(c, 3111111111111119, -15), number(c, 1000000000000000, -14)},
+		{"div", number(c, 1, 0), number(c, 2, 0), number(c, 5, -1)},
+		{"div", number(c, 1, 0), number(c, 10, 0), number(c, 1, -1)},
+		{"div", number(c, 1, 0), number(c, -10, 0), number(c, -1, -1)},
+		{"div", number(c, 0, 0), number(c, 100, 0), number(c, 0, 0)},
+		{"div", number(c, 1414213562373095, -10), number(c, 1414213562373095, -10), number(c, 1, 0)},
+		{"div", number(c, 9999999999999999, 0), number(c, 1000000000000000, 0), number(c, 9999999999999999, -15)},
+		{"div", number(c, 2, 0), number(c, 3, 0), number(c, 6666666666666667, -16)},
+		{"div", number(c, -2, 0), number(c, 3, 0), number(c, -6666666666666667, -16)},
+	} {
+		var result Number
+		var err error
+		switch test.op {
+		case "add":
+			result, err = test.a.Add(test.b, RoundToNearest)
+		case "mul":
+			result, err = test.a.Mul(test.b, RoundToNearest)
+		case "div":
+			result, err = test.a.Div(test.b, RoundToNearest)
+		}
+		c.Assert(err, IsNil)
+		c.Check(result.String(), Equals, test.expected.String(), Commentf("%s %s %s", test.op, test.a, test.b))
+	}
+
+	var ar Arithmetic
+	twenty, sixty := amountCheck("20/USD").Value, amountCheck("60/USD").Value
+	three, threeDrops := amountCheck("3/USD").Value, amountCheck("0.000003/XRP").Value
+	// XRP are in drops, so the results are 60 and 20 drops when native
+	for _, test := range []struct {
+		native            bool
+		product, quotient string
+	}{
+		{false, "6000000000000000e-14", "2000000000000000e-14"},
+		{true, "60", "20"},
+	} {
+		for _, b := range []*Value{three, threeDrops} {
+			c.Check(arithmeticResult(ar.Multiply(*twenty, *b, test.native)), Equals, test.product)
+			c.Check(arithmeticResult(ar.Divide(*sixty, *b, test.native)), Equals, test.quotient)
+		}
+	}
+}
+
+// Cases from rippled's Number_test.cpp for the other rounding modes
+func (s *ArithmeticSuite) TestRippledRoundingModes(c *C) {
+	for _, test := range []struct {
+		mode                                                        RoundingMode
+		root2, negativeRoot2, product, twoThirds, negativeTwoThirds string
+	}{
+		{RoundToNearest, "2000000000000000e-15", "-2000000000000000e-15", "1000000000000000e-14", "6666666666666667e-16", "-6666666666666667e-16"},
+		{RoundTowardsZero, "1999999999999999e-15", "-1999999999999999e-15", "9999999999999999e-15", "6666666666666666e-16", "-6666666666666666e-16"},
+		{RoundDownward, "1999999999999999e-15", "-2000000000000000e-15", "9999999999999999e-15", "6666666666666666e-16", "-6666666666666667e-16"},
+		{RoundUpward, "2000000000000000e-15", "-1999999999999999e-15", "1000000000000000e-14", "6666666666666667e-16", "-6666666666666666e-16"},
+	} {
+		root2 := number(c, 1414213562373095, -15)
+		for _, op := range []struct {
+			a, b     Number
+			div      bool
+			expected string
+		}{
+			{root2, root2, false, test.root2},
+			{root2.Negate(), root2, false, test.negativeRoot2},
+			{root2.Negate(), root2.Negate(), false, test.root2},
+			{number(c, 3214285714285706, -15), number(c, 3111111111111119, -15), false, test.product},
+			{number(c, 2, 0), number(c, 3, 0), true, test.twoThirds},
+			{number(c, -2, 0), number(c, 3, 0), true, test.negativeTwoThirds},
+			{number(c, 1, 0), number(c, -10, 0), true, "-1000000000000000e-16"},
+		} {
+			var result Number
+			var err error
+			if op.div {
+				result, err = op.a.Div(op.b, test.mode)
+			} else {
+				result, err = op.a.Mul(op.b, test.mode)
+			}
+			c.Assert(err, IsNil)
+			c.Check(arithmeticResult(result.Value(false, test.mode)), Equals, op.expected, Commentf("%d %s %s", test.mode, op.a, op.b))
+		}
+	}
+
+	// test_to_integer
+	for _, test := range []struct {
+		mode     RoundingMode
+		expected []string
+	}{
+		{RoundToNearest, []string{"2", "1", "2", "2", "1", "0", "0", "-2", "-1", "-2", "-2", "-1", "0", "0"}},
+		{RoundTowardsZero, []string{"1", "1", "1", "2", "0", "0", "0", "-1", "-1", "-1", "-2", "0", "0", "0"}},
+		{RoundDownward, []string{"1", "1", "1", "2", "0", "0", "0", "-2", "-2", "-2", "-3", "-1", "-1", "-1"}},
+		{RoundUpward, []string{"2", "2", "2", "3", "1", "1", "1", "-1", "-1", "-1", "-2", "0", "0", "0"}},
+	} {
+		for i, m := range []int64{15, 14, 16, 25, 6, 5, 4, -15, -14, -16, -25, -6, -5, -4} {
+			c.Check(arithmeticResult(number(c, m, -1).Value(true, test.mode)), Equals, test.expected[i], Commentf("%d %de-1", test.mode, m))
+		}
+	}
+}
+
+// Number::operator/ truncates the quotient to 17 digits before rounding, so
+// an inexact quotient can round as a tie. The exact quotient is
+// 0.35938570710705945038... and would round up.
+func (s *ArithmeticSuite) TestDivisionTie(c *C) {
+	q, err := number(c, 2174459890961016, -16).Div(number(c, 6050490734494496, -16), RoundToNearest)
+	c.Assert(err, IsNil)
+	c.Check(q.String(), Equals, number(c, 3593857071070594, -16).String())
+	ar := Arithmetic{UniversalNumber: true}
+	v, err := ar.Divide(*amountCheck("0.2174459890961016/USD").Value, *amountCheck("0.6050490734494496/USD").Value, false)
+	c.Check(arithmeticResult(v, err), Equals, "3593857071070594e-16")
+}
+
+// The divisions and multiplications of rippled's STAmount_test.cpp
+// testRounding, which only logs its results. They predate
+// fixUniversalNumber, under which only the Strict variants round towards
+// zero.
+func (s *ArithmeticSuite) TestRippledRounding(c *C) {
+	one, two, three := amountCheck("1/USD").Value, amountCheck("2/USD").Value, amountCheck("3/USD").Value
+	third, thirdUp := amountCheck("0.3333333333333333/USD").Value, amountCheck("0.3333333333333334/USD").Value
+	var legacy Arithmetic
+	universal := Arithmetic{UniversalNumber: true}
+	for _, test := range []struct {
+		op       func(Value, Value, bool, bool) (*Value, error)
+		a, b     *Value
+		roundUp  bool
+		expected string
+	}{
+		{legacy.DivRound, one, three, false, "3333333333333333e-16"},
+		{legacy.DivRound, one, three, true, "3333333333333334e-16"},
+		{legacy.DivRound, two, three, false, "6666666666666666e-16"},
+		{legacy.DivRound, two, three, true, "6666666666666667e-16"},
+		{legacy.MulRound, third, three, false, "9999999999999999e-16"},
+		{legacy.MulRound, thirdUp, three, true, "1000000000000001e-15"},
+		{legacy.DivRoundStrict, two, three, false, "6666666666666666e-16"},
+		{legacy.MulRoundStrict, thirdUp, three, true, "1000000000000001e-15"},
+		{universal.DivRoundStrict, one, three, false, "3333333333333333e-16"},
+		{universal.DivRoundStrict, one, three, true, "3333333333333334e-16"},
+		{universal.DivRoundStrict, two, three, false, "6666666666666666e-16"},
+		{universal.DivRoundStrict, two, three, true, "6666666666666667e-16"},
+		{universal.MulRoundStrict, third, three, false, "9999999999999999e-16"},
+		{universal.MulRoundStrict, thirdUp, three, true, "1000000000000001e-15"},
+	} {
+		c.Check(arithmeticResult(test.op(*test.a, *test.b, false, test.roundUp)), Equals, test.expected, Commentf("%s %s %v", test.a, test.b, test.roundUp))
+	}
+}
@@ -67,79 +67,57 @@ func (m *BalanceMap) Add(account *Account, counterparty *Account, balance, chang
 	(*m)[*account].Add(counterparty, balance, change, currency)
 }
 
+// Balances returns the changes to XRP and issued currency balances made by
+// a transaction, keyed by account. Both sides of a trust line are included,
+// so an issuer sees the opposite of each change its holders see. XRP
+// balances have a zero CounterParty. The fee is not included, see FeeBurnt.
 func (txm *TransactionWithMetaData) Balances() (BalanceMap, error) {
-	if txm.GetTransactionType() != OFFER_CREATE && txm.GetTransactionType() != PAYMENT {
-		return nil, nil
-	}
 	balanceMap := BalanceMap{}
-	account := txm.Transaction.GetBase().Account
-	for _, node := range txm.MetaData.AffectedNodes {
+	account, fee := txm.FeeBurnt()
+	for i := range txm.MetaData.AffectedNodes {
+		effect := &txm.MetaData.AffectedNodes[i]
 		switch {
-		case node.CreatedNode != nil:
-			switch node.CreatedNode.LedgerEntryType {
-			case ACCOUNT_ROOT:
-				created := node.CreatedNode.NewFields.(*AccountRoot)
-				balanceMap.Add(created.Account, &zeroAccount, &zeroNative, created.Balance, &zeroCurrency)
-			case RIPPLE_STATE:
-				// New trust line
-				state := node.CreatedNode.NewFields.(*RippleState)
-				balanceMap.Add(&state.LowLimit.Issuer, &state.HighLimit.Issuer, state.Balance.Value, state.Balance.Value, &state.Balance.Currency)
-				balanceMap.Add(&state.HighLimit.Issuer, &state.LowLimit.Issuer, state.Balance.Value.Negate(), state.Balance.Value.Negate(), &state.Balance.Currency)
-			}
-		case node.DeletedNode != nil:
-			switch node.DeletedNode.LedgerEntryType {
-			case RIPPLE_STATE:
-				//?
-			case ACCOUNT_ROOT:
-				return nil, fmt.Errorf("Deleted AccountRoot!")
+		case effect.CreatedNode != nil && effect.CreatedNode.NewFields == nil:
+			continue
+		case effect.ModifiedNode != nil && effect.ModifiedNode.PreviousFields == nil:
+			// No change
+			continue
+		}
+		_, final, previous, state := effect.AffectedNode()
+		switch current := final.(type) {
+		case *AccountRoot:
+			change, err := balanceChange(current.Balance, previous.(*AccountRoot).Balance, state)
+			if err != nil {
+				return nil, err
 			}
-		case node.ModifiedNode != nil:
-			if node.ModifiedNode.PreviousFields == nil {
-				// No change
+			if change == nil {
+				// OwnerCount, Sequence or flag change
 				continue
 			}
-			switch node.ModifiedNode.LedgerEntryType {
-			case ACCOUNT_ROOT:
-				// Changed XRP Balance
-				var (
-					previous = node.ModifiedNode.PreviousFields.(*AccountRoot)
-					current  = node.ModifiedNode.FinalFields.(*AccountRoot)
-				)
-				if previous.Balance == nil {
-					// ownercount change
-					continue
-				}
-				change, err := NewAmount(int64(current.Balance.num - previous.Balance.num))
-				if err != nil {
-					return nil, err
-				}
-				// Add fee and see if change is non-zero
-				if current.Account.Equals(account) {
-					change.Value, err = change.Value.Add(txm.GetBase().Fee)
-					if err != nil {
-						return nil, err
-					}
-				}
-				if change.num != 0 {
-					balanceMap.Add(current.Account, &zeroAccount, current.Balance, change.Value, &zeroCurrency)
-				}
-			case RIPPLE_STATE:
-				// Changed non-native balance
-				var (
-					previous = node.ModifiedNode.PreviousFields.(*RippleState)
-					current  = node.ModifiedNode.FinalFields.(*RippleState)
-				)
-				if previous.Balance == nil {
-					//flag change
-					continue
-				}
-				change, err := current.Balance.Subtract(previous.Balance)
-				if err != nil {
+			if current.Account.Equals(account) {
+				// Remove the fee so that only transfers remain
+				if change, err = change.Add(*fee); err != nil {
 					return nil, err
 				}
-				balanceMap.Add(&current.LowLimit.Issuer, &current.HighLimit.Issuer, current.Balance.Value, change.Value, &current.Balance.Currency)
-				balanceMap.Add(&current.HighLimit.Issuer, &current.LowLimit.Issuer, current.Balance.Value.Negate(), change.Value.Negate(), &current.Balance.Currency)
 			}
+			if !change.IsZero() {
+				balanceMap.Add(current.Account, &zeroAccount, current.Balance, change, &zeroCurrency)
+			}
+		case *RippleState:
+			var before *Value
+			if prev := previous.(*RippleState).Balance; prev != nil {
+				before = prev.Value
+			}
+			change, err := balanceChange(current.Balance.Value, before, state)
+			if err != nil {
+				return nil, err
+			}
+			if change == nil || change.IsZero() {
+				// Limit, quality or flag change
+				continue
+			}
+			balanceMap.Add(&current.LowLimit.Issuer, &current.HighLimit.Issuer, current.Balance.Value, change, &current.Balance.Currency)
+			balanceMap.Add(&current.HighLimit.Issuer, &current.LowLimit.Issuer, current.Balance.Value.Negate(), change.Negate(), &current.Balance.Currency)
 		}
 	}
 	for _, balances := range balanceMap {
@@ -147,3 +125,137 @@ func (txm *TransactionWithMetaData) Balances() (BalanceMap, error) {
 	}
 	return balanceMap, nil
 }
+
+// Lock is a change to the value held by an Escrow or a PayChannel. While
+// locked the value belongs to neither Account nor Destination, so it is
+// missing from Balances.
+type Lock struct {
+	LedgerEntryType LedgerEntryType
+	LedgerIndex     Hash256
+	Account         Account
+	Destination     Account
+	Locked          Amount // Held after the transaction
+	Change          Value
+}
+
+func (l Lock) String() string {
+	return fmt.Sprintf("%s: %s Account: %-34s Destination: %-34s Locked: %20s Change: %20s", l.LedgerEntryType, l.LedgerIndex, l.Account, l.Destination, l.Locked, l.Change)
+}
+
+// Locks returns the changes to value held in escrows and payment channels
+// made by a transaction. A PayChannel holds its Amount less the Balance
+// already paid to the Destination. Deleted entries hold nothing afterwards.
+// Together with Balances and FeeBurnt this accounts for all XRP moved.
+func (txm *TransactionWithMetaData) Locks() ([]Lock, error) {
+	var locks []Lock
+	for i := range txm.MetaData.AffectedNodes {
+		effect := &txm.MetaData.AffectedNodes[i]
+		switch {
+		case effect.CreatedNode != nil && effect.CreatedNode.NewFields == nil:
+			continue
+		case effect.ModifiedNode != nil && effect.ModifiedNode.PreviousFields == nil:
+			// No change
+			continue
+		}
+		node, final, previous, state := effect.AffectedNode()
+		var (
+			account, destination *Account
+			before, after        *Amount
+			err                  error
+		)
+		switch current := final.(type) {
+		case *Escrow:
+			account, destination = &current.Account, &current.Destination
+			if current.Amount.Value == nil {
+				continue
+			}
+			after, before = &current.Amount, &current.Amount
+			if prev := previous.(*Escrow).Amount; prev.Value != nil {
+				before = &prev
+			}
+		case *PayChannel:
+			account, destination = current.Account, current.Destination
+			prev := previous.(*PayChannel)
+			if after, err = channelLocked(current.Amount, current.Balance); err != nil {
+				return nil, err
+			}
+			if before, err = channelLocked(firstAmount(prev.Amount, current.Amount), firstAmount(prev.Balance, current.Balance)); err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+		if after == nil || account == nil || destination == nil || node.LedgerIndex == nil {
+			continue
+		}
+		switch state {
+		case Created:
+			before = after.ZeroClone()
+		case Deleted:
+			after = after.ZeroClone()
+		}
+		change, err := after.Subtract(before)
+		if err != nil {
+			return nil, err
+		}
+		if change.IsZero() {
+			continue
+		}
+		locks = append(locks, Lock{
+			LedgerEntryType: final.GetLedgerEntryType(),
+			LedgerIndex:     *node.LedgerIndex,
+			Account:         *account,
+			Destination:     *destination,
+			Locked:          *after,
+			Change:          *change.Value,
+		})
+	}
+	return locks, nil
+}
+
+// channelLocked returns the part of a channel's amount not yet paid out.
+// A missing balance is zero.
+func channelLocked(amount, balance *Amount) (*Amount, error) {
+	switch {
+	case amount == nil:
+		return nil, nil
+	case balance == nil:
+		return amount, nil
+	default:
+		return amount.Subtract(balance)
+	}
+}
+
+func firstAmount(amounts ...*Amount) *Amount {
+	for _, amount := range amounts {
+		if amount != nil {
+			return amount
+		}
+	}
+	return nil
+}
+
+// FeeBurnt returns the account which paid the transaction fee and the
+// XRP destroyed.
+func (txm *TransactionWithMetaData) FeeBurnt() (Account, *Value) {
+	base := txm.GetBase()
+	if base.Fee.IsZero() {
+		return base.Account, zeroNative.Clone()
+	}
+	return base.Account, base.Fee.Clone()
+}
+
+// balanceChange returns the change from previous to current, or nil if
+// previous is missing for a node that was not created by the transaction.
+func balanceChange(current, previous *Value, state LedgerEntryState) (*Value, error) {
+	switch {
+	case current == nil:
+		return nil, nil
+	case previous != nil:
+		return current.Subtract(*previous)
+	case state == Created:
+		return current.Clone(), nil
+	default:
+		return nil, nil
+	}
+}
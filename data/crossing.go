@@ -0,0 +1,460 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+)
+
+// OfferSimulator predicts the outcome of an OfferCreate crossing an order
+// book without submitting it. It is an approximation of rippled's legacy
+// Taker, which crossed offers before the FlowCross amendment. Since then
+// rippled crosses offers with the payment engine's BookStep, which can also
+// cross through XRP between two IOU books and consume AMM liquidity, neither
+// of which the simulator models, and which can round the last digit of a
+// partial fill differently.
+//
+// Offers is a snapshot of the book being crossed, in which each offer's
+// TakerGets is the OfferCreate's TakerPays, such as the offers returned by
+// book_offers. The OwnerFunds or TakerGetsFunded of an owner's first offer
+// limit what all of that owner's offers can deliver.
+type OfferSimulator struct {
+	Arithmetic
+	Offers []OrderBookOffer
+	// TransferRates holds the TransferRate of the issuers of both assets.
+	// Issuers which are missing charge no fee.
+	TransferRates map[Account]uint32
+	// TakerFunds is what the taker holds of its TakerGets, nil if unlimited
+	TakerFunds *Value
+	// CloseTime is the close time of the parent ledger, used to expire
+	// offers. Zero ignores expiration.
+	CloseTime uint32
+}
+
+// Fill is the crossing of a single offer of the book
+type Fill struct {
+	Offer     *OrderBookOffer
+	TakerPaid *Amount // Debited from the taker, including any transfer fee
+	TakerGot  *Amount // Credited to the taker
+	OwnerPaid *Amount // Debited from the offer's owner, including any transfer fee
+	OwnerGot  *Amount // Credited to the offer's owner
+	Consumed  bool    // The offer was taken completely, or its owner's funds ran out, and removed from the book
+}
+
+// Crossing is the predicted outcome of an OfferCreate
+type Crossing struct {
+	Result    TransactionResult
+	Fills     []Fill
+	Removed   []*OrderBookOffer // Unfunded and expired offers removed from the book
+	TakerPaid *Amount           // The sum of the TakerPaid of the Fills
+	TakerGot  *Amount           // The sum of the TakerGot of the Fills
+	Remaining *Offer            // The offer placed in the book, if any
+}
+
+type bookOffer struct {
+	*OrderBookOffer
+	quality ExchangeRate
+}
+
+// offerFlow is what moves when an offer is crossed
+type offerFlow struct {
+	in, out  *Value // The amounts taken from the offer
+	takerIn  *Value // in with the transfer fee paid by the taker
+	ownerOut *Value // out with the transfer fee paid by the owner
+}
+
+type taker struct {
+	*OfferSimulator
+	sell                      bool
+	remainingIn, remainingOut *Value
+	funds                     *Value
+}
+
+// Simulate crosses tx with the book. The offers of the simulator are not
+// modified. If tx is killed the offers which would have been crossed are
+// not reported, but unfunded and expired offers are still removed.
+func (s *OfferSimulator) Simulate(tx *OfferCreate) (*Crossing, error) {
+	if !tx.TakerPays.IsPositive() || tx.TakerPays.IsZero() || !tx.TakerGets.IsPositive() || tx.TakerGets.IsZero() {
+		return nil, fmt.Errorf("Bad offer: %s for %s", tx.TakerPays, tx.TakerGets)
+	}
+	var flags TransactionFlag
+	if tx.Flags != nil {
+		flags = *tx.Flags
+	}
+	passive, sell := flags&TxPassive > 0, flags&TxSell > 0
+	in, out := tx.TakerGets.Issue(), tx.TakerPays.Issue()
+	crossing := &Crossing{
+		Result:    TesSUCCESS,
+		TakerPaid: tx.TakerGets.ZeroClone(),
+		TakerGot:  tx.TakerPays.ZeroClone(),
+	}
+	switch {
+	case s.CloseTime > 0 && tx.Expiration != nil && *tx.Expiration <= s.CloseTime:
+		crossing.Result = TecEXPIRED
+		return crossing, nil
+	case s.TakerFunds != nil && (s.TakerFunds.IsZero() || s.TakerFunds.IsNegative()):
+		crossing.Result = TecUNFUNDED_OFFER
+		return crossing, nil
+	}
+	threshold, err := s.quality(*tx.TakerGets.Value, *tx.TakerPays.Value)
+	if err != nil {
+		return nil, err
+	}
+	book, err := s.book(in, out)
+	if err != nil {
+		return nil, err
+	}
+	t := &taker{
+		OfferSimulator: s,
+		sell:           sell,
+		remainingIn:    tx.TakerGets.Value.Clone(),
+		remainingOut:   tx.TakerPays.Value.Clone(),
+	}
+	if s.TakerFunds != nil {
+		t.funds = s.TakerFunds.Clone()
+	}
+	funds := make(map[Account]*Value)
+	for _, offer := range book {
+		if t.done() {
+			break
+		}
+		owner := *offer.Account
+		if s.CloseTime > 0 && offer.Expiration != nil && *offer.Expiration <= s.CloseTime {
+			crossing.Removed = append(crossing.Removed, offer.OrderBookOffer)
+			continue
+		}
+		rateIn, rateOut := s.transferRate(in, tx.Account, owner), s.transferRate(out, owner, tx.Account)
+		ownerFunds, ok := funds[owner]
+		if !ok {
+			if ownerFunds, err = s.ownerFunds(offer.OrderBookOffer, rateOut); err != nil {
+				return nil, err
+			}
+			funds[owner] = ownerFunds
+		}
+		if ownerFunds != nil && exhausted(ownerFunds) {
+			crossing.Removed = append(crossing.Removed, offer.OrderBookOffer)
+			continue
+		}
+		if offer.quality > threshold || (passive && offer.quality == threshold) {
+			break
+		}
+		fill, err := t.cross(offer, ownerFunds, rateIn, rateOut, in, out)
+		if err != nil {
+			return nil, err
+		}
+		if ownerFunds != nil {
+			if funds[owner], err = s.Subtract(*ownerFunds, *fill.OwnerPaid.Value); err != nil {
+				return nil, err
+			}
+			// The rest of the offer is unfunded and removed
+			fill.Consumed = fill.Consumed || exhausted(funds[owner])
+		}
+		if crossing.TakerPaid, err = crossing.TakerPaid.Add(fill.TakerPaid); err != nil {
+			return nil, err
+		}
+		if crossing.TakerGot, err = crossing.TakerGot.Add(fill.TakerGot); err != nil {
+			return nil, err
+		}
+		crossing.Fills = append(crossing.Fills, *fill)
+	}
+	wanted := t.remainingOut
+	if sell {
+		wanted = t.remainingIn
+	}
+	switch {
+	case flags&TxFillOrKill > 0 && !exhausted(wanted):
+		crossing.Result = TecKILLED
+		crossing.Fills = nil
+		crossing.TakerPaid = tx.TakerGets.ZeroClone()
+		crossing.TakerGot = tx.TakerPays.ZeroClone()
+		return crossing, nil
+	case flags&TxImmediateOrCancel > 0:
+		if len(crossing.Fills) == 0 {
+			crossing.Result = TecKILLED
+		}
+		return crossing, nil
+	case t.done():
+		return crossing, nil
+	}
+	crossing.Remaining, err = t.remaining(tx, len(crossing.Fills) > 0, threshold)
+	return crossing, err
+}
+
+// book returns the offers in the order they are crossed
+func (s *OfferSimulator) book(in, out Issue) ([]bookOffer, error) {
+	book := make([]bookOffer, len(s.Offers))
+	for i := range s.Offers {
+		offer := &s.Offers[i]
+		switch {
+		case offer.Account == nil || offer.TakerPays == nil || offer.TakerGets == nil:
+			return nil, fmt.Errorf("Incomplete offer: %d", i)
+		case offer.TakerPays.Issue() != in || offer.TakerGets.Issue() != out:
+			return nil, fmt.Errorf("Offer not in book: %s for %s", offer.TakerPays, offer.TakerGets)
+		case offer.BookDirectory != nil:
+			book[i] = bookOffer{offer, GetQuality(*offer.BookDirectory)}
+		default:
+			quality, err := s.quality(*offer.TakerPays.Value, *offer.TakerGets.Value)
+			if err != nil {
+				return nil, err
+			}
+			book[i] = bookOffer{offer, quality}
+		}
+	}
+	sort.SliceStable(book, func(i, j int) bool {
+		return book[i].quality < book[j].quality
+	})
+	return book, nil
+}
+
+// ownerFunds returns what the owner of offer can deliver, nil if unlimited
+func (s *OfferSimulator) ownerFunds(offer *OrderBookOffer, rate *Value) (*Value, error) {
+	gets := offer.TakerGets
+	switch {
+	case !gets.IsNative() && *offer.Account == gets.Issuer:
+		return nil, nil
+	case !offer.OwnerFunds.IsZero() && gets.IsNative():
+		return offer.OwnerFunds.Native()
+	case !offer.OwnerFunds.IsZero():
+		return offer.OwnerFunds.Clone(), nil
+	case offer.TakerGetsFunded != nil:
+		return s.transfer(*offer.TakerGetsFunded.Value, rate)
+	default:
+		return nil, nil
+	}
+}
+
+// transferRate returns the rate charged when from sends issue to to, nil
+// if there is no fee.
+func (s *OfferSimulator) transferRate(issue Issue, from, to Account) *Value {
+	if issue.Currency.IsNative() || from == issue.Issuer || to == issue.Issuer {
+		return nil
+	}
+	rate, ok := s.TransferRates[issue.Issuer]
+	if !ok || rate == 0 || rate == 1000000000 {
+		return nil
+	}
+	v := newValue(false, false, uint64(rate), -9)
+	v.canonicalise()
+	return v
+}
+
+func (s *OfferSimulator) transfer(v Value, rate *Value) (*Value, error) {
+	if rate == nil {
+		return v.Clone(), nil
+	}
+	return s.Multiply(v, *rate, v.IsNative())
+}
+
+func (s *OfferSimulator) untransfer(v Value, rate *Value) (*Value, error) {
+	if rate == nil {
+		return v.Clone(), nil
+	}
+	return s.Divide(v, *rate, v.IsNative())
+}
+
+// quality returns the ratio of in to out as stored in a book directory,
+// as rippled's getRate.
+func (ar Arithmetic) quality(in, out Value) (ExchangeRate, error) {
+	if out.IsZero() {
+		return 0, nil
+	}
+	rate, err := ar.Divide(in, out, false)
+	if err != nil {
+		return 0, err
+	}
+	if rate.IsZero() {
+		return 0, nil
+	}
+	return ExchangeRate(uint64(rate.offset+100)<<56 | rate.num), nil
+}
+
+// ceilOut limits the out of an offer rounding its in up, as rippled's
+// Quality::ceil_out.
+func (s *OfferSimulator) ceilOut(in, out Value, quality ExchangeRate, limit Value) (*Value, *Value, error) {
+	if !limit.Less(out) {
+		return in.Clone(), out.Clone(), nil
+	}
+	in2, err := s.MulRound(limit, *quality.Value(), in.IsNative(), true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if in.Less(*in2) {
+		in2 = in.Clone()
+	}
+	return in2, limit.Clone(), nil
+}
+
+// ceilIn limits the in of an offer rounding its out up, as rippled's
+// Quality::ceil_in.
+func (s *OfferSimulator) ceilIn(in, out Value, quality ExchangeRate, limit Value) (*Value, *Value, error) {
+	if !limit.Less(in) {
+		return in.Clone(), out.Clone(), nil
+	}
+	out2, err := s.DivRound(limit, *quality.Value(), out.IsNative(), true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if out.Less(*out2) {
+		out2 = out.Clone()
+	}
+	return limit.Clone(), out2, nil
+}
+
+// flow returns how much of an offer can be taken, as rippled's
+// BasicTaker::flow_iou_to_iou. Fees are skipped for XRP, which makes it
+// equivalent to the XRP variants.
+func (t *taker) flow(in, out Value, quality ExchangeRate, funds, rateIn, rateOut *Value) (*offerFlow, error) {
+	var err error
+	f := &offerFlow{in: in.Clone(), out: out.Clone()}
+	if f.takerIn, err = t.transfer(in, rateIn); err != nil {
+		return nil, err
+	}
+	if f.ownerOut, err = t.transfer(out, rateOut); err != nil {
+		return nil, err
+	}
+	// Clamp on the owner's funds
+	if funds != nil && funds.Less(*f.ownerOut) {
+		limit, err := t.untransfer(*funds, rateOut)
+		if err != nil {
+			return nil, err
+		}
+		if f.in, f.out, err = t.ceilOut(*f.in, *f.out, quality, *limit); err != nil {
+			return nil, err
+		}
+		f.ownerOut = funds.Clone()
+		if f.takerIn, err = t.transfer(*f.in, rateIn); err != nil {
+			return nil, err
+		}
+	}
+	// Clamp on what the taker still wants
+	if !t.sell && t.remainingOut.Less(*f.out) {
+		if f.in, f.out, err = t.ceilOut(*f.in, *f.out, quality, *t.remainingOut); err != nil {
+			return nil, err
+		}
+		if f.takerIn, err = t.transfer(*f.in, rateIn); err != nil {
+			return nil, err
+		}
+		if f.ownerOut, err = t.transfer(*f.out, rateOut); err != nil {
+			return nil, err
+		}
+	}
+	// Clamp on what the taker has left to pay, before the transfer fee
+	if t.remainingIn.Less(*f.in) {
+		if f.in, f.out, err = t.ceilIn(*f.in, *f.out, quality, *t.remainingIn); err != nil {
+			return nil, err
+		}
+		if f.takerIn, err = t.transfer(*f.in, rateIn); err != nil {
+			return nil, err
+		}
+		if f.ownerOut, err = t.transfer(*f.out, rateOut); err != nil {
+			return nil, err
+		}
+	}
+	// Clamp on the taker's funds, which pay the transfer fee
+	if t.funds != nil && t.funds.Less(*f.takerIn) {
+		limit, err := t.untransfer(*t.funds, rateIn)
+		if err != nil {
+			return nil, err
+		}
+		if f.in, f.out, err = t.ceilIn(*f.in, *f.out, quality, *limit); err != nil {
+			return nil, err
+		}
+		f.takerIn = t.funds.Clone()
+		if f.ownerOut, err = t.transfer(*f.out, rateOut); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// cross takes what it can of offer and updates what remains for the taker
+func (t *taker) cross(offer bookOffer, funds, rateIn, rateOut *Value, in, out Issue) (*Fill, error) {
+	f, err := t.flow(*offer.TakerPays.Value, *offer.TakerGets.Value, offer.quality, funds, rateIn, rateOut)
+	if err != nil {
+		return nil, err
+	}
+	if t.remainingOut, err = t.Subtract(*t.remainingOut, *f.out); err != nil {
+		return nil, err
+	}
+	if t.remainingIn, err = t.Subtract(*t.remainingIn, *f.in); err != nil {
+		return nil, err
+	}
+	if t.funds != nil {
+		if t.funds, err = t.Subtract(*t.funds, *f.takerIn); err != nil {
+			return nil, err
+		}
+	}
+	leftIn, err := t.Subtract(*offer.TakerPays.Value, *f.in)
+	if err != nil {
+		return nil, err
+	}
+	leftOut, err := t.Subtract(*offer.TakerGets.Value, *f.out)
+	if err != nil {
+		return nil, err
+	}
+	return &Fill{
+		Offer:     offer.OrderBookOffer,
+		TakerPaid: newAmount(f.takerIn, in.Currency, in.Issuer),
+		TakerGot:  newAmount(f.out, out.Currency, out.Issuer),
+		OwnerPaid: newAmount(f.ownerOut, out.Currency, out.Issuer),
+		OwnerGot:  newAmount(f.in, in.Currency, in.Issuer),
+		Consumed:  exhausted(leftIn) || exhausted(leftOut),
+	}, nil
+}
+
+func (t *taker) done() bool {
+	return (!t.sell && exhausted(t.remainingOut)) || exhausted(t.remainingIn) || (t.funds != nil && exhausted(t.funds))
+}
+
+// remaining returns the offer placed in the book after crossing, as
+// rippled's BasicTaker::remaining_offer.
+func (t *taker) remaining(tx *OfferCreate, crossed bool, quality ExchangeRate) (*Offer, error) {
+	pays, gets := tx.TakerPays.Clone(), tx.TakerGets.Clone()
+	var err error
+	switch {
+	case !crossed:
+	case t.sell:
+		gets.Value = t.remainingIn.Clone()
+		if pays.Value, err = t.DivRound(*t.remainingIn, *quality.Value(), pays.IsNative(), true); err != nil {
+			return nil, err
+		}
+	default:
+		pays.Value = t.remainingOut.Clone()
+		if gets.Value, err = t.MulRound(*t.remainingOut, *quality.Value(), gets.IsNative(), true); err != nil {
+			return nil, err
+		}
+	}
+	rate, err := t.quality(*pays.Value, *gets.Value)
+	if err != nil {
+		return nil, err
+	}
+	directory, err := GetBookDirectoryIndex(pays.Issue(), gets.Issue(), rate)
+	if err != nil {
+		return nil, err
+	}
+	var flags LedgerEntryFlag
+	if tx.Flags != nil && *tx.Flags&TxPassive > 0 {
+		flags |= LsPassive
+	}
+	if t.sell {
+		flags |= LsSell
+	}
+	sequence := tx.Sequence
+	if tx.TicketSequence != nil {
+		sequence = *tx.TicketSequence
+	}
+	account := tx.Account
+	return &Offer{
+		leBase:        leBase{LedgerEntryType: OFFER},
+		Flags:         &flags,
+		Account:       &account,
+		Sequence:      &sequence,
+		TakerPays:     pays,
+		TakerGets:     gets,
+		BookDirectory: directory,
+		Expiration:    tx.Expiration,
+	}, nil
+}
+
+func exhausted(v *Value) bool {
+	return v.IsZero() || v.IsNegative()
+}
@@ -0,0 +1,300 @@
+package data
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type CrossingSuite struct{}
+
+var _ = Suite(&CrossingSuite{})
+
+func crossingOffer(owner string, sequence uint32, gets, pays string, funds string) OrderBookOffer {
+	account := accountCheck(owner)
+	offer := OrderBookOffer{
+		Offer: Offer{
+			Account:   &account,
+			Sequence:  &sequence,
+			TakerGets: usdCheck(gets),
+			TakerPays: amountCheck(pays + "/XRP"),
+		},
+	}
+	if funds != "" {
+		offer.OwnerFunds = *usdCheck(funds).Value
+	}
+	return offer
+}
+
+func crossingBook() []OrderBookOffer {
+	return []OrderBookOffer{
+		crossingOffer("rJMNfiJTwXHcMdB4SpxMgL3mvV4xUVHDnd", 1, "100", "60", ""),
+		crossingOffer("rNPRNzBB92BVpAhhZr4iXDTveCgV5Pofm9", 2, "30", "12", ""),
+		crossingOffer("rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", 3, "50", "22.5", ""),
+	}
+}
+
+func crossingTx(pays, gets string, flags TransactionFlag) *OfferCreate {
+	return &OfferCreate{
+		TxBase: TxBase{
+			TransactionType: OFFER_CREATE,
+			Flags:           &flags,
+			Account:         accountCheck(testAccount),
+			Sequence:        7,
+		},
+		TakerPays: *usdCheck(pays),
+		TakerGets: *amountCheck(gets + "/XRP"),
+	}
+}
+
+func (s *CrossingSuite) TestBuy(c *C) {
+	sim := &OfferSimulator{Offers: crossingBook()}
+	crossing, err := sim.Simulate(crossingTx("100", "50", 0))
+	c.Assert(err, IsNil)
+	c.Check(crossing.Result, Equals, TesSUCCESS)
+	c.Assert(crossing.Fills, HasLen, 2)
+	c.Check(*crossing.Fills[0].Offer.Sequence, Equals, uint32(2))
+	c.Check(crossing.Fills[0].Consumed, Equals, true)
+	c.Check(crossing.Fills[1].Consumed, Equals, true)
+	c.Check(crossing.TakerPaid.String(), Equals, "34.5/XRP")
+	c.Check(crossing.TakerGot.String(), Equals, "80/USD/"+testIssuer)
+	c.Assert(crossing.Remaining, NotNil)
+	c.Check(crossing.Remaining.TakerPays.String(), Equals, "20/USD/"+testIssuer)
+	c.Check(crossing.Remaining.TakerGets.String(), Equals, "10/XRP")
+	c.Check(*crossing.Remaining.Sequence, Equals, uint32(7))
+	c.Check(GetQuality(*crossing.Remaining.BookDirectory).Value().String(), Equals, "0.000002")
+}
+
+func (s *CrossingSuite) TestPartialFill(c *C) {
+	sim := &OfferSimulator{Offers: crossingBook()}
+	crossing, err := sim.Simulate(crossingTx("40", "50", 0))
+	c.Assert(err, IsNil)
+	c.Assert(crossing.Fills, HasLen, 2)
+	c.Check(crossing.Fills[1].Consumed, Equals, false)
+	c.Check(crossing.Fills[1].TakerPaid.String(), Equals, "4.5/XRP")
+	c.Check(crossing.TakerGot.String(), Equals, "40/USD/"+testIssuer)
+	c.Check(crossing.Remaining, IsNil)
+}
+
+func (s *CrossingSuite) TestSell(c *C) {
+	sim := &OfferSimulator{Offers: crossingBook()}
+	crossing, err := sim.Simulate(crossingTx("40", "50", TxSell))
+	c.Assert(err, IsNil)
+	c.Assert(crossing.Fills, HasLen, 3)
+	c.Check(crossing.Fills[2].Consumed, Equals, false)
+	c.Check(crossing.Fills[2].TakerGot.String(), Equals, "25.83333333333334/USD/"+testIssuer)
+	c.Check(crossing.TakerPaid.String(), Equals, "50/XRP")
+	c.Check(crossing.Remaining, IsNil)
+}
+
+func (s *CrossingSuite) TestPassive(c *C) {
+	sim := &OfferSimulator{Offers: crossingBook()[1:2]}
+	crossing, err := sim.Simulate(crossingTx("30", "12", 0))
+	c.Assert(err, IsNil)
+	c.Check(crossing.Fills, HasLen, 1)
+	c.Check(crossing.Remaining, IsNil)
+	crossing, err = sim.Simulate(crossingTx("30", "12", TxPassive))
+	c.Assert(err, IsNil)
+	c.Check(crossing.Fills, HasLen, 0)
+	c.Assert(crossing.Remaining, NotNil)
+	c.Check(*crossing.Remaining.Flags, Equals, LsPassive)
+}
+
+func (s *CrossingSuite) TestFillOrKill(c *C) {
+	sim := &OfferSimulator{Offers: crossingBook()}
+	crossing, err := sim.Simulate(crossingTx("100", "50", TxFillOrKill))
+	c.Assert(err, IsNil)
+	c.Check(crossing.Result, Equals, TecKILLED)
+	c.Check(crossing.Fills, HasLen, 0)
+	c.Check(crossing.TakerGot.IsZero(), Equals, true)
+	crossing, err = sim.Simulate(crossingTx("40", "50", TxFillOrKill))
+	c.Assert(err, IsNil)
+	c.Check(crossing.Result, Equals, TesSUCCESS)
+	c.Check(crossing.Fills, HasLen, 2)
+}
+
+func (s *CrossingSuite) TestImmediateOrCancel(c *C) {
+	sim := &OfferSimulator{Offers: crossingBook()}
+	crossing, err := sim.Simulate(crossingTx("100", "50", TxImmediateOrCancel))
+	c.Assert(err, IsNil)
+	c.Check(crossing.Result, Equals, TesSUCCESS)
+	c.Check(crossing.Fills, HasLen, 2)
+	c.Check(crossing.Remaining, IsNil)
+	crossing, err = sim.Simulate(crossingTx("100", "1", TxImmediateOrCancel))
+	c.Assert(err, IsNil)
+	c.Check(crossing.Result, Equals, TecKILLED)
+}
+
+func (s *CrossingSuite) TestTransferRate(c *C) {
+	sim := &OfferSimulator{
+		Offers:        crossingBook()[1:2],
+		TransferRates: map[Account]uint32{accountCheck(testIssuer): 1002000000},
+	}
+	crossing, err := sim.Simulate(crossingTx("30", "12", 0))
+	c.Assert(err, IsNil)
+	c.Assert(crossing.Fills, HasLen, 1)
+	c.Check(crossing.Fills[0].TakerGot.String(), Equals, "30/USD/"+testIssuer)
+	c.Check(crossing.Fills[0].OwnerPaid.String(), Equals, "30.06/USD/"+testIssuer)
+}
+
+func (s *CrossingSuite) TestTransferRateIn(c *C) {
+	owner := accountCheck("rJMNfiJTwXHcMdB4SpxMgL3mvV4xUVHDnd")
+	sequence := uint32(1)
+	sim := &OfferSimulator{
+		Offers: []OrderBookOffer{{Offer: Offer{
+			Account:   &owner,
+			Sequence:  &sequence,
+			TakerGets: amountCheck("50/XRP"),
+			TakerPays: usdCheck("100"),
+		}}},
+		TransferRates: map[Account]uint32{accountCheck(testIssuer): 1002000000},
+	}
+	tx := crossingTx("100", "50", 0)
+	tx.TakerPays, tx.TakerGets = *amountCheck("50/XRP"), *usdCheck("100")
+	crossing, err := sim.Simulate(tx)
+	c.Assert(err, IsNil)
+	c.Assert(crossing.Fills, HasLen, 1)
+	c.Check(crossing.Fills[0].Consumed, Equals, true)
+	c.Check(crossing.Fills[0].TakerPaid.String(), Equals, "100.2/USD/"+testIssuer)
+	c.Check(crossing.Fills[0].OwnerGot.String(), Equals, "100/USD/"+testIssuer)
+	c.Check(crossing.TakerGot.String(), Equals, "50/XRP")
+	c.Check(crossing.Remaining, IsNil)
+
+	funds := usdCheck("50.1").Value
+	sim.TakerFunds = funds
+	crossing, err = sim.Simulate(tx)
+	c.Assert(err, IsNil)
+	c.Assert(crossing.Fills, HasLen, 1)
+	c.Check(crossing.Fills[0].Consumed, Equals, false)
+	c.Check(crossing.Fills[0].TakerPaid.String(), Equals, "50.1/USD/"+testIssuer)
+	c.Check(crossing.Fills[0].OwnerGot.String(), Equals, "50/USD/"+testIssuer)
+	c.Check(crossing.TakerGot.String(), Equals, "25/XRP")
+}
+
+func (s *CrossingSuite) TestOwnerFundsJSON(c *C) {
+	var offers []OrderBookOffer
+	c.Assert(json.Unmarshal([]byte(`[
+		{"TakerGets": "1000000", "TakerPays": {"currency": "USD", "issuer": "`+testIssuer+`", "value": "1"}, "owner_funds": "2500000"},
+		{"TakerGets": {"currency": "USD", "issuer": "`+testIssuer+`", "value": "1"}, "TakerPays": "1000000", "owner_funds": "1234.123456789"}
+	]`), &offers), IsNil)
+	c.Check(offers[0].OwnerFunds.String(), Equals, "2.5")
+	c.Check(offers[0].OwnerFunds.IsNative(), Equals, true)
+	c.Check(offers[1].OwnerFunds.String(), Equals, "1234.123456789")
+	c.Check(offers[1].OwnerFunds.IsNative(), Equals, false)
+}
+
+func (s *CrossingSuite) TestOwnerFunds(c *C) {
+	owner := "rNPRNzBB92BVpAhhZr4iXDTveCgV5Pofm9"
+	sim := &OfferSimulator{
+		Offers: []OrderBookOffer{
+			crossingOffer(owner, 1, "30", "12", "20"),
+			crossingOffer(owner, 2, "50", "22.5", ""),
+			crossingOffer("rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", 3, "50", "24", ""),
+		},
+	}
+	crossing, err := sim.Simulate(crossingTx("40", "50", 0))
+	c.Assert(err, IsNil)
+	c.Assert(crossing.Fills, HasLen, 2)
+	c.Check(crossing.Fills[0].TakerGot.String(), Equals, "20/USD/"+testIssuer)
+	c.Check(crossing.Fills[0].TakerPaid.String(), Equals, "8/XRP")
+	// The rest of the first offer is unfunded
+	c.Check(crossing.Fills[0].Consumed, Equals, true)
+	c.Assert(crossing.Removed, HasLen, 1)
+	c.Check(*crossing.Removed[0].Sequence, Equals, uint32(2))
+	c.Check(crossing.Fills[1].TakerPaid.String(), Equals, "9.6/XRP")
+}
+
+func (s *CrossingSuite) TestExpired(c *C) {
+	book := crossingBook()
+	expiration := uint32(1000)
+	book[1].Expiration = &expiration
+	sim := &OfferSimulator{Offers: book, CloseTime: 1000}
+	crossing, err := sim.Simulate(crossingTx("100", "50", 0))
+	c.Assert(err, IsNil)
+	c.Check(crossing.Removed, HasLen, 1)
+	c.Check(crossing.Fills, HasLen, 1)
+	tx := crossingTx("100", "50", 0)
+	tx.Expiration = &expiration
+	crossing, err = sim.Simulate(tx)
+	c.Assert(err, IsNil)
+	c.Check(crossing.Result, Equals, TecEXPIRED)
+}
+
+func (s *CrossingSuite) TestWrongBook(c *C) {
+	sim := &OfferSimulator{Offers: crossingBook()}
+	tx := crossingTx("100", "50", 0)
+	tx.TakerPays, tx.TakerGets = tx.TakerGets, tx.TakerPays
+	_, err := sim.Simulate(tx)
+	c.Check(err, NotNil)
+}
+
+// metadataBook rebuilds the offers crossed by txm as they were before it
+// applied, with the funds of their owners from the trust lines it modified,
+// and returns them with their final fields and whether they were deleted.
+func metadataBook(txm *TransactionWithMetaData) ([]OrderBookOffer, map[Account]*Offer, map[Account]bool) {
+	var book []OrderBookOffer
+	after := make(map[Account]*Offer)
+	deleted := make(map[Account]bool)
+	funds := make(map[Account]*Value)
+	for i := range txm.MetaData.AffectedNodes {
+		_, final, previous, state := txm.MetaData.AffectedNodes[i].AffectedNode()
+		switch f := final.(type) {
+		case *Offer:
+			if state == Created {
+				continue
+			}
+			offer := *f
+			offer.TakerGets, offer.TakerPays = previous.(*Offer).TakerGets, previous.(*Offer).TakerPays
+			book = append(book, OrderBookOffer{Offer: offer})
+			after[*f.Account] = f
+			deleted[*f.Account] = state == Deleted
+		case *RippleState:
+			if balance := previous.(*RippleState).Balance; balance != nil && state == Modified {
+				funds[f.HighLimit.Issuer] = balance.Value.Negate()
+			}
+		}
+	}
+	for i := range book {
+		if f, ok := funds[*book[i].Account]; ok {
+			book[i].OwnerFunds = *f
+		}
+	}
+	return book, after, deleted
+}
+
+// The OfferCreate of ledger 3398077 crossed eight BTC offers with the legacy
+// Taker, three of which were limited by the funds of their owners.
+func (s *CrossingSuite) TestMetadata(c *C) {
+	txm := loadTransaction(c, "testdata/transaction_offercreate.json")
+	tx := txm.Transaction.(*OfferCreate)
+	book, after, deleted := metadataBook(txm)
+	sim := &OfferSimulator{
+		Offers:        book,
+		TransferRates: map[Account]uint32{tx.TakerPays.Issuer: 1002000000},
+	}
+	crossing, err := sim.Simulate(tx)
+	c.Assert(err, IsNil)
+	c.Assert(crossing.Fills, HasLen, len(book))
+	for i, fill := range crossing.Fills {
+		final := after[*fill.Offer.Account]
+		paid, err := fill.Offer.TakerPays.Subtract(final.TakerPays)
+		c.Assert(err, IsNil)
+		got, err := fill.Offer.TakerGets.Subtract(final.TakerGets)
+		c.Assert(err, IsNil)
+		c.Check(fill.TakerPaid.String(), Equals, paid.String())
+		c.Check(fill.Consumed, Equals, deleted[*fill.Offer.Account])
+		if i == len(crossing.Fills)-1 {
+			// rippled took one less in the last digit of the partial fill
+			c.Check(got.String(), Equals, "2.032398983215035/BTC/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+			c.Check(fill.TakerGot.String(), Equals, "2.032398983215036/BTC/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+			c.Check(*fill.Offer.Sequence, Equals, uint32(2308))
+			continue
+		}
+		c.Check(fill.TakerGot.String(), Equals, got.String())
+		if !fill.Offer.OwnerFunds.IsZero() && fill.Offer.OwnerFunds.Less(*fill.Offer.TakerGets.Value) {
+			c.Check(fill.OwnerPaid.Value.String(), Equals, fill.Offer.OwnerFunds.String())
+		}
+	}
+	c.Check(crossing.TakerPaid.String(), Equals, "516418.508783/XRP")
+	c.Check(crossing.Remaining, IsNil)
+}
@@ -6,8 +6,9 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math"
-	"strings"
 	"unicode"
+
+	"github.com/rubblelabs/ripple/crypto"
 )
 
 type Currency [20]byte
@@ -19,33 +20,64 @@ const (
 	CT_DEMURRAGE CurrencyType = 2
 	CT_HEX       CurrencyType = 3
 	CT_UNKNOWN   CurrencyType = 4
+	CT_LPTOKEN   CurrencyType = 5
 )
 
-var zeroCurrency Currency
+var (
+	zeroCurrency Currency
+	// The standard code XRP is reserved and cannot be issued
+	xrpCurrencyCode = Currency{12: 'X', 13: 'R', 14: 'P'}
+)
 
-// Accepts currency as either a 3 character code
-// or a 40 character hex string
+// Accepts currency as either a 3 character code, a 40 character hex
+// string or a name of up to 20 ASCII characters, which is packed into the
+// currency code and padded with zeros.
 func NewCurrency(s string) (Currency, error) {
 	if s == "XRP" {
 		return zeroCurrency, nil
 	}
 	var currency Currency
-	switch len(s) {
-	case 3:
+	switch {
+	case len(s) == 3:
 		copy(currency[12:], []byte(s))
 		return currency, nil
-	case 40:
+	case len(s) == 40:
 		c, err := hex.DecodeString(s)
 		if err != nil {
 			return currency, fmt.Errorf("Bad Currency: %s", s)
 		}
 		copy(currency[:], c)
 		return currency, nil
+	case len(s) > 3 && len(s) <= len(currency) && isPrintableASCII([]byte(s)):
+		copy(currency[:], []byte(s))
+		return currency, nil
 	default:
 		return currency, fmt.Errorf("Bad Currency: %s", s)
 	}
 }
 
+func isPrintableASCII(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 || c > 0x7E {
+			return false
+		}
+	}
+	return true
+}
+
+// GetLPTokenCurrency returns the currency of the LP tokens of the AMM for
+// a pair of currencies. The order of the currencies does not matter.
+func GetLPTokenCurrency(a, b Currency) Currency {
+	if b.Less(a) {
+		a, b = b, a
+	}
+	hash := crypto.Sha512Half(append(append([]byte(nil), a[:]...), b[:]...))
+	var currency Currency
+	currency[0] = 0x03
+	copy(currency[1:], hash)
+	return currency
+}
+
 func (a Currency) Compare(b Currency) int {
 	return bytes.Compare(a[:], b[:])
 }
@@ -88,6 +120,8 @@ func (c Currency) Type() CurrencyType {
 		return CT_STANDARD
 	case c[0] == 0x01:
 		return CT_DEMURRAGE
+	case c[0] == 0x03:
+		return CT_LPTOKEN
 	case c[0] >= 0x80:
 		return CT_HEX
 	default:
@@ -95,6 +129,33 @@ func (c Currency) Type() CurrencyType {
 	}
 }
 
+// IsLPToken returns true for the currency of the LP tokens of an AMM
+func (c Currency) IsLPToken() bool {
+	return c.Type() == CT_LPTOKEN
+}
+
+// Name returns the name packed into a non-standard currency code, which is
+// up to 20 printable ASCII characters followed by zeros
+func (c Currency) Name() (string, bool) {
+	if c.Type() != CT_UNKNOWN {
+		return "", false
+	}
+	name := bytes.TrimRight(c[:], "\x00")
+	if len(name) <= 3 || !isPrintableASCII(name) {
+		return "", false
+	}
+	return string(name), true
+}
+
+// CheckIssued returns an error if c cannot be the currency of an issued
+// amount
+func (c Currency) CheckIssued() error {
+	if c.IsNative() || c == xrpCurrencyCode {
+		return fmt.Errorf("XRP cannot be an issued currency")
+	}
+	return nil
+}
+
 func (c Currency) Rate(seconds uint32) float64 {
 	if c.Type() != CT_DEMURRAGE {
 		return 1.0
@@ -110,7 +171,11 @@ const secondsInYear = uint32(3600 * 24 * 365)
 
 // Currency in human parsable form
 // Demurrage is formatted, for example, as XAU (0.50%pa)
+// and names packed into non-standard codes as the name
 func (c Currency) String() string {
+	if name, ok := c.Name(); ok {
+		return name
+	}
 	if c.Type() != CT_DEMURRAGE {
 		return c.Machine()
 	}
@@ -130,13 +195,6 @@ func (c Currency) Machine() string {
 			}
 		}
 		return string(c[12:15])
-	case CT_UNKNOWN:
-		return strings.Map(func(r rune) rune {
-			if unicode.IsPrint(r) {
-				return r
-			}
-			return -1
-		}, string(c[:]))
 	default:
 		return string(b2h(c[:]))
 	}
@@ -47,3 +47,75 @@ func (s *CurrencySuite) TestCurrencyTypes(c *C) {
 	c.Assert(wtf.String(), Equals, "0000000000000000000000007F80010000000000")
 	c.Assert(wtf.Type(), Equals, CT_STANDARD)
 }
+
+func (s *CurrencySuite) TestNonStandard(c *C) {
+	solo, err := NewCurrency("534F4C4F00000000000000000000000000000000")
+	c.Assert(err, IsNil)
+	c.Check(solo.Type(), Equals, CT_UNKNOWN)
+	c.Check(solo.Machine(), Equals, "534F4C4F00000000000000000000000000000000")
+	c.Check(solo.String(), Equals, "SOLO")
+	name, ok := solo.Name()
+	c.Check(ok, Equals, true)
+	c.Check(name, Equals, "SOLO")
+
+	packed, err := NewCurrency("SOLO")
+	c.Assert(err, IsNil)
+	c.Check(packed, Equals, solo)
+	_, err = NewCurrency("ABCDEFGHIJKLMNOPQRSTU")
+	c.Check(err, ErrorMatches, "Bad Currency: .*")
+
+	amount, err := NewAmount("1/SOLO/rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz")
+	c.Assert(err, IsNil)
+	c.Check(amount.String(), Equals, "1/SOLO/rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz")
+	b, err := amount.MarshalJSON()
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `{"value":"1","currency":"534F4C4F00000000000000000000000000000000","issuer":"rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz"}`)
+	var decoded Amount
+	c.Assert(decoded.UnmarshalJSON(b), IsNil)
+	c.Check(decoded.Currency, Equals, solo)
+
+	asset, err := NewAsset("SOLO/rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz")
+	c.Assert(err, IsNil)
+	c.Check(asset.Currency, Equals, solo.Machine())
+	c.Check(asset.Matches(amount), Equals, true)
+}
+
+func (s *CurrencySuite) TestDemurrageAsset(c *C) {
+	amount, err := NewAmount("1/015841551A748AD2C1F76FF6ECB0CCCD00000000/rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz")
+	c.Assert(err, IsNil)
+	asset := amount.Asset()
+	c.Check(asset.Currency, Equals, "015841551A748AD2C1F76FF6ECB0CCCD00000000")
+	c.Check(asset.Matches(amount), Equals, true)
+	other, err := NewAmount("1/0158415500000000C1F76FF6ECB0BAC600000000/rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz")
+	c.Assert(err, IsNil)
+	c.Check(other.Currency.String(), Equals, amount.Currency.String())
+	c.Check(asset.Matches(other), Equals, false)
+}
+
+func (s *CurrencySuite) TestLPToken(c *C) {
+	usd, err := NewCurrency("USD")
+	c.Assert(err, IsNil)
+	lp := GetLPTokenCurrency(usd, zeroCurrency)
+	c.Check(lp, Equals, GetLPTokenCurrency(zeroCurrency, usd))
+	c.Check(lp.Type(), Equals, CT_LPTOKEN)
+	c.Check(lp.IsLPToken(), Equals, true)
+	c.Check(lp.String(), Equals, lp.Machine())
+	c.Check(lp.Machine()[:2], Equals, "03")
+	c.Check(usd.IsLPToken(), Equals, false)
+}
+
+func (s *CurrencySuite) TestIssuedXRP(c *C) {
+	_, err := NewAmount("1/XRP/rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz")
+	c.Check(err, ErrorMatches, "XRP cannot be an issued currency")
+	_, err = NewAmount("1/0000000000000000000000005852500000000000/rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz")
+	c.Check(err, ErrorMatches, "XRP cannot be an issued currency")
+	var amount Amount
+	err = amount.UnmarshalJSON([]byte(`{"value":"1","currency":"XRP","issuer":"rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz"}`))
+	c.Check(err, ErrorMatches, "XRP cannot be an issued currency")
+	err = amount.UnmarshalJSON([]byte(`{"value":"1","currency":"0000000000000000000000000000000000000000","issuer":"rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz"}`))
+	c.Check(err, ErrorMatches, "XRP cannot be an issued currency")
+	_, err = NewAsset("XRP/rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz")
+	c.Check(err, ErrorMatches, "XRP cannot be an issued currency")
+	_, err = NewAmount("1/XRP")
+	c.Check(err, IsNil)
+}
@@ -167,6 +167,9 @@ func readCompressedInnerNode(r Reader, typ NodeType, nodeId Hash256) (*InnerNode
 	inner.Type = typ
 	var entry CompressedNodeEntry
 	for read(r, &entry) == nil {
+		if int(entry.Pos) >= len(inner.Children) {
+			return nil, fmt.Errorf("Bad inner node position: %d", entry.Pos)
+		}
 		inner.Children[entry.Pos] = entry.Hash
 	}
 	copy(inner.Id[:], nodeId.Bytes())
@@ -0,0 +1,73 @@
+package data
+
+import (
+	"fmt"
+)
+
+// IsPartialPayment returns true if the transaction is a Payment with the
+// tfPartialPayment flag set. The Amount of such a Payment is an upper
+// bound on what was delivered, not what was delivered.
+func (txm *TransactionWithMetaData) IsPartialPayment() bool {
+	payment, ok := txm.Transaction.(*Payment)
+	return ok && payment.Flags != nil && *payment.Flags&TxPartialPayment != 0
+}
+
+// DeliveredAmount returns the amount received by the destination of a
+// Payment, CheckCash or AccountDelete. It is safe to credit a deposit with
+// the result, whether the transaction was decoded from JSON or binary.
+// The delivered_amount reported by the server is used when present,
+// otherwise it is computed from the balance changes in the metadata.
+// A transaction which did not succeed delivers zero.
+func (txm *TransactionWithMetaData) DeliveredAmount() (*Amount, error) {
+	var (
+		receiver Account
+		expected *Amount
+	)
+	switch tx := txm.Transaction.(type) {
+	case *Payment:
+		receiver, expected = tx.Destination, &tx.Amount
+	case *CheckCash:
+		receiver, expected = tx.Account, tx.Amount
+		if expected == nil {
+			expected = tx.DeliverMin
+		}
+	case *AccountDelete:
+		receiver, expected = tx.Destination, &Amount{Value: zeroNative.Clone()}
+	default:
+		return nil, fmt.Errorf("No delivered amount for: %s", txm.GetTransactionType())
+	}
+	if expected == nil {
+		return nil, fmt.Errorf("CheckCash has no Amount or DeliverMin")
+	}
+	switch {
+	case !txm.MetaData.TransactionResult.Success():
+		return expected.ZeroClone(), nil
+	case txm.MetaData.DeliveredAmount != nil:
+		return txm.MetaData.DeliveredAmount, nil
+	case txm.GetTransactionType() == PAYMENT && !txm.IsPartialPayment():
+		return expected, nil
+	}
+	balances, err := txm.Balances()
+	if err != nil {
+		return nil, err
+	}
+	delivered := expected.ZeroClone()
+	received, ok := balances[receiver]
+	if !ok {
+		return delivered, nil
+	}
+	for _, balance := range *received {
+		switch {
+		case balance.Currency != expected.Currency:
+			continue
+		case expected.IsNative():
+		case expected.Issuer != receiver && balance.CounterParty != expected.Issuer:
+			// The receiver only accepts other issuers when it is the issuer
+			continue
+		}
+		if delivered.Value, err = delivered.Value.Add(balance.Change); err != nil {
+			return nil, err
+		}
+	}
+	return delivered, nil
+}
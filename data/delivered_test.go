@@ -0,0 +1,57 @@
+package data
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type DeliveredSuite struct{}
+
+var _ = Suite(&DeliveredSuite{})
+
+func (s *DeliveredSuite) TestPayment(c *C) {
+	txm := loadTransaction(c, "testdata/transaction_payment_with_rippling.json")
+	c.Check(txm.IsPartialPayment(), Equals, false)
+	delivered, err := txm.DeliveredAmount()
+	c.Assert(err, IsNil)
+	c.Check(delivered.String(), Equals, "20/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+
+	// Compute from the affected nodes
+	partial := TxPartialPayment
+	txm.GetBase().Flags = &partial
+	c.Check(txm.IsPartialPayment(), Equals, true)
+	delivered, err = txm.DeliveredAmount()
+	c.Assert(err, IsNil)
+	c.Check(delivered.String(), Equals, "20/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+
+	txm.MetaData.TransactionResult = TecPATH_PARTIAL
+	delivered, err = txm.DeliveredAmount()
+	c.Assert(err, IsNil)
+	c.Check(delivered.IsZero(), Equals, true)
+}
+
+func (s *DeliveredSuite) TestAccountDelete(c *C) {
+	txm := loadTransaction(c, "testdata/transaction_account_delete.json")
+	delivered, err := txm.DeliveredAmount()
+	c.Assert(err, IsNil)
+	c.Check(delivered.String(), Equals, "23.006084/XRP")
+	txm.MetaData.DeliveredAmount = nil
+	delivered, err = txm.DeliveredAmount()
+	c.Assert(err, IsNil)
+	c.Check(delivered.String(), Equals, "23.006084/XRP")
+}
+
+func (s *DeliveredSuite) TestNotDelivered(c *C) {
+	txm := loadTransaction(c, "testdata/transaction_account_set.json")
+	_, err := txm.DeliveredAmount()
+	c.Check(err, NotNil)
+}
+
+func (s *DeliveredSuite) TestUnavailable(c *C) {
+	var meta MetaData
+	c.Assert(json.Unmarshal([]byte(`{"AffectedNodes":[],"TransactionIndex":0,"TransactionResult":"tesSUCCESS","delivered_amount":"unavailable"}`), &meta), IsNil)
+	c.Check(meta.DeliveredAmount, IsNil)
+	c.Assert(json.Unmarshal([]byte(`{"AffectedNodes":[],"TransactionIndex":0,"TransactionResult":"tesSUCCESS","delivered_amount":"1000"}`), &meta), IsNil)
+	c.Check(meta.DeliveredAmount.String(), Equals, "0.001/XRP")
+}
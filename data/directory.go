@@ -0,0 +1,141 @@
+package data
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// GetBookDirectoryIndex returns the index of the first directory page of
+// the order book in which the taker pays one asset and gets the other,
+// holding the offers with the supplied quality.
+func GetBookDirectoryIndex(pays, gets Issue, quality ExchangeRate) (*Hash256, error) {
+	base, err := GetBookIndex(pays.Currency, gets.Currency, pays.Issuer, gets.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	return GetQualityIndex(*base, uint64(quality)), nil
+}
+
+// GetQuality returns the quality encoded in the low 64 bits of an order book
+// directory's root index.
+func GetQuality(index Hash256) ExchangeRate {
+	return ExchangeRate(binary.BigEndian.Uint64(index[24:]))
+}
+
+// GetBookBase returns the index of an order book directory with its
+// quality removed. All the directories of a book share the same base.
+func GetBookBase(index Hash256) Hash256 {
+	return *GetQualityIndex(index, 0)
+}
+
+// GetQualityNext returns the index just past the last possible quality of
+// the book which index belongs to. Directories of the book have indexes
+// in the range [GetBookBase(index), GetQualityNext(index)).
+func GetQualityNext(index Hash256) Hash256 {
+	next := GetBookBase(index)
+	for i := 23; i >= 0; i-- {
+		if next[i]++; next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+// IsBookDirectory returns true if d is a page of an order book rather
+// than of an owner directory.
+func (d *Directory) IsBookDirectory() bool {
+	return d.TakerPaysCurrency != nil
+}
+
+// Quality returns the quality of the offers in a book directory. The
+// ExchangeRate is used if present, otherwise it is taken from the RootIndex.
+func (d *Directory) Quality() (ExchangeRate, error) {
+	switch {
+	case !d.IsBookDirectory():
+		return 0, fmt.Errorf("Not a book directory")
+	case d.ExchangeRate != nil:
+		return *d.ExchangeRate, nil
+	case d.RootIndex != nil:
+		return GetQuality(*d.RootIndex), nil
+	default:
+		return 0, fmt.Errorf("Book directory has no ExchangeRate or RootIndex")
+	}
+}
+
+// BookDirectories returns the directory pages of the order book with the
+// supplied base index found in les. Pages are ordered by quality, best
+// first, and then by following the IndexNext links from each root page.
+// les can be the output of one or more ledger_data requests.
+func (les LedgerEntrySlice) BookDirectories(base Hash256) ([]*Directory, error) {
+	base = GetBookBase(base)
+	var roots []*Directory
+	pages := make(map[Hash256]*Directory)
+	for _, le := range les {
+		dir, ok := le.(*Directory)
+		if !ok || !dir.IsBookDirectory() || dir.RootIndex == nil || GetBookBase(*dir.RootIndex) != base {
+			continue
+		}
+		index, err := LeafIndex(dir)
+		if err != nil {
+			return nil, err
+		}
+		pages[*index] = dir
+		if *index == *dir.RootIndex {
+			roots = append(roots, dir)
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		return GetQuality(*roots[i].RootIndex) < GetQuality(*roots[j].RootIndex)
+	})
+	dirs := make([]*Directory, 0, len(pages))
+	for _, root := range roots {
+		for dir := root; ; {
+			dirs = append(dirs, dir)
+			if dir.IndexNext == nil || *dir.IndexNext == 0 {
+				break
+			}
+			next, err := GetDirectoryNodeIndex(*root.RootIndex, dir.IndexNext)
+			if err != nil {
+				return nil, err
+			}
+			if dir = pages[*next]; dir == nil {
+				return nil, fmt.Errorf("Missing directory page: %s", next)
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// BookOffers returns the offers of the order book with the supplied base
+// index found in les, in the order they would be consumed.
+func (les LedgerEntrySlice) BookOffers(base Hash256) ([]*Offer, error) {
+	dirs, err := les.BookDirectories(base)
+	if err != nil {
+		return nil, err
+	}
+	offers := make(map[Hash256]*Offer)
+	for _, le := range les {
+		if offer, ok := le.(*Offer); ok && offer.BookDirectory != nil && GetBookBase(*offer.BookDirectory) == GetBookBase(base) {
+			index, err := LeafIndex(offer)
+			if err != nil {
+				return nil, err
+			}
+			offers[*index] = offer
+		}
+	}
+	var book []*Offer
+	for _, dir := range dirs {
+		if dir.Indexes == nil {
+			continue
+		}
+		for _, index := range *dir.Indexes {
+			offer, ok := offers[index]
+			if !ok {
+				return nil, fmt.Errorf("Missing offer: %s", index)
+			}
+			book = append(book, offer)
+		}
+	}
+	return book, nil
+}
@@ -0,0 +1,66 @@
+package data
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	. "gopkg.in/check.v1"
+)
+
+type DirectorySuite struct{}
+
+var _ = Suite(&DirectorySuite{})
+
+func (s *DirectorySuite) TestExchangeRate(c *C) {
+	ledger := loadLedger(c, "testdata/ledger_6000000.json")
+	var checked int
+	for _, le := range ledger.AccountState {
+		offer, ok := le.(*Offer)
+		if !ok {
+			continue
+		}
+		rate, err := NewExchangeRate(offer.TakerPays, offer.TakerGets)
+		c.Assert(err, IsNil)
+		c.Check(rate, Equals, GetQuality(*offer.BookDirectory), Commentf("%s %s %s", offer.TakerPays, offer.TakerGets, offer.BookDirectory))
+		checked++
+	}
+	c.Check(checked > 0, Equals, true)
+}
+
+func (s *DirectorySuite) TestBookOffers(c *C) {
+	ledger := loadLedger(c, "testdata/ledger_6000000.json")
+	usd := amountCheck("1/USD/rrrrrrrrrrrrrrrrrrrrBZbvji").Issue()
+	_, err := hex.Decode(usd.Issuer[:], []byte("2B6C42A95B3F7EE1971E4A10098E8F1B5F66AA08"))
+	c.Assert(err, IsNil)
+	base, err := GetBookDirectoryIndex(usd, Issue{}, 0)
+	c.Assert(err, IsNil)
+	c.Check(base.String(), Equals, "2FB4904ACFB96228FC002335B1B5A4C5584D9D727BBE82140000000000000000")
+	next := GetQualityNext(*base)
+	c.Check(next.String(), Equals, "2FB4904ACFB96228FC002335B1B5A4C5584D9D727BBE82150000000000000000")
+	dirs, err := ledger.AccountState.BookDirectories(*base)
+	c.Assert(err, IsNil)
+	c.Assert(dirs, HasLen, 2)
+	for i, expected := range []string{"4F0415EB4EA0C727", "5003BAF82D03A000"} {
+		quality, err := dirs[i].Quality()
+		c.Assert(err, IsNil)
+		c.Check(fmt.Sprintf("%016X", uint64(quality)), Equals, expected)
+		c.Check(*GetQualityIndex(*base, uint64(quality)), Equals, *dirs[i].RootIndex)
+	}
+	offers, err := ledger.AccountState.BookOffers(*base)
+	c.Assert(err, IsNil)
+	c.Assert(offers, HasLen, 2)
+	c.Check(GetBookBase(*offers[0].BookDirectory), Equals, *base)
+	c.Check(GetQuality(*offers[0].BookDirectory) < GetQuality(*offers[1].BookDirectory), Equals, true)
+	_, err = (&Directory{}).Quality()
+	c.Check(err, NotNil)
+}
+
+func (s *DirectorySuite) TestExchangeRateValue(c *C) {
+	rate, err := NewExchangeRate(amountCheck("3/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL"), amountCheck("2/XRP"))
+	c.Assert(err, IsNil)
+	c.Check(rate.Value().String(), Equals, "0.0000015")
+	c.Check(ExchangeRate(0).Value().IsZero(), Equals, true)
+	zero, err := NewExchangeRate(amountCheck("3/XRP"), amountCheck("0/XRP"))
+	c.Assert(err, IsNil)
+	c.Check(zero, Equals, ExchangeRate(0))
+}
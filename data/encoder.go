@@ -82,7 +82,7 @@ func writeRaw(w io.Writer, value interface{}, ignoreSigningFields bool) error {
 		return nil
 	case *InnerNode:
 		return write(w, v.Children)
-	case *Validation:
+	case *Validation, *Manifest:
 		return encode(w, value, ignoreSigningFields)
 	case *Proposal:
 		if ignoreSigningFields {
@@ -112,7 +112,7 @@ func writeRaw(w io.Writer, value interface{}, ignoreSigningFields bool) error {
 		if err := encode(w, v, ignoreSigningFields); err != nil {
 			return err
 		}
-		index, err := LedgerIndex(v)
+		index, err := LeafIndex(v)
 		if err != nil {
 			return err
 		}
@@ -175,6 +175,10 @@ func getFields(v *reflect.Value, depth int, ignoreSigningFields bool) fieldSlice
 		if fieldName == "LedgerEntryType" && depth > 1 && typ.Name() == "leBase" {
 			continue
 		}
+		// The index is a key, not a field of the LedgerEntry
+		if fieldName == "LedgerIndex" && typ.Name() == "leBase" {
+			continue
+		}
 		encoding := reverseEncodings[fieldName]
 		if ignoreSigningFields && encoding.SigningField() {
 			continue
@@ -2,15 +2,21 @@ package data
 
 import (
 	"fmt"
+	"strings"
 )
 
 type TransactionFlag uint32
 type LedgerEntryFlag uint32
 
+// AccountSetFlag is the value of the SetFlag and ClearFlag fields of an
+// AccountSet. Unlike the other flags, only one can be set at a time.
+type AccountSetFlag uint32
+
 // Transaction Flags
 const (
 	//Universal flags
 	TxCanonicalSignature TransactionFlag = 0x80000000
+	TxInnerBatch         TransactionFlag = 0x40000000
 
 	// Payment flags
 	TxNoDirectRipple TransactionFlag = 0x00010000
@@ -18,7 +24,8 @@ const (
 	TxLimitQuality   TransactionFlag = 0x00040000
 	TxCircle         TransactionFlag = 0x00080000 // Not implemented
 
-	// AccountSet flags
+	// AccountSet flags. The TxSet values predate AccountSetFlag and are
+	// SetFlag and ClearFlag values rather than bits of Flags.
 	TxSetRequireDest   TransactionFlag = 0x00000001
 	TxSetRequireAuth   TransactionFlag = 0x00000002
 	TxSetDisallowXRP   TransactionFlag = 0x00000003
@@ -41,11 +48,13 @@ const (
 	TxSell              TransactionFlag = 0x00080000
 
 	// TrustSet flags
-	TxSetAuth       TransactionFlag = 0x00010000
-	TxSetNoRipple   TransactionFlag = 0x00020000
-	TxClearNoRipple TransactionFlag = 0x00040000
-	TxSetFreeze     TransactionFlag = 0x00100000
-	TxClearFreeze   TransactionFlag = 0x00200000
+	TxSetAuth         TransactionFlag = 0x00010000
+	TxSetNoRipple     TransactionFlag = 0x00020000
+	TxClearNoRipple   TransactionFlag = 0x00040000
+	TxSetFreeze       TransactionFlag = 0x00100000
+	TxClearFreeze     TransactionFlag = 0x00200000
+	TxSetDeepFreeze   TransactionFlag = 0x00400000
+	TxClearDeepFreeze TransactionFlag = 0x00800000
 
 	// EnableAmendments flags
 	TxGotMajority  TransactionFlag = 0x00010000
@@ -54,12 +63,71 @@ const (
 	// PaymentChannelClaim flags
 	TxRenew TransactionFlag = 0x00010000
 	TxClose TransactionFlag = 0x00020000
+
+	// NFTokenMint flags
+	TxBurnable     TransactionFlag = 0x00000001
+	TxOnlyXRP      TransactionFlag = 0x00000002
+	TxTrustLine    TransactionFlag = 0x00000004
+	TxTransferable TransactionFlag = 0x00000008
+	TxMutable      TransactionFlag = 0x00000010
+
+	// NFTokenCreateOffer flags
+	TxSellNFToken TransactionFlag = 0x00000001
+
+	// AMMDeposit and AMMWithdraw flags
+	TxLPToken             TransactionFlag = 0x00010000
+	TxWithdrawAll         TransactionFlag = 0x00020000
+	TxOneAssetWithdrawAll TransactionFlag = 0x00040000
+	TxSingleAsset         TransactionFlag = 0x00080000
+	TxTwoAsset            TransactionFlag = 0x00100000
+	TxOneAssetLPToken     TransactionFlag = 0x00200000
+	TxLimitLPToken        TransactionFlag = 0x00400000
+	TxTwoAssetIfEmpty     TransactionFlag = 0x00800000
+)
+
+// AccountSet SetFlag and ClearFlag values
+const (
+	AsfRequireDest                  AccountSetFlag = 1
+	AsfRequireAuth                  AccountSetFlag = 2
+	AsfDisallowXRP                  AccountSetFlag = 3
+	AsfDisableMaster                AccountSetFlag = 4
+	AsfAccountTxnID                 AccountSetFlag = 5
+	AsfNoFreeze                     AccountSetFlag = 6
+	AsfGlobalFreeze                 AccountSetFlag = 7
+	AsfDefaultRipple                AccountSetFlag = 8
+	AsfDepositAuth                  AccountSetFlag = 9
+	AsfAuthorizedNFTokenMinter      AccountSetFlag = 10
+	AsfDisallowIncomingNFTokenOffer AccountSetFlag = 12
+	AsfDisallowIncomingCheck        AccountSetFlag = 13
+	AsfDisallowIncomingPayChan      AccountSetFlag = 14
+	AsfDisallowIncomingTrustline    AccountSetFlag = 15
+	AsfAllowTrustLineClawback       AccountSetFlag = 16
 )
 
-var txFlagNames = map[TransactionType][]struct {
+var accountSetFlagNames = map[AccountSetFlag]string{
+	AsfRequireDest:                  "RequireDest",
+	AsfRequireAuth:                  "RequireAuth",
+	AsfDisallowXRP:                  "DisallowXRP",
+	AsfDisableMaster:                "DisableMaster",
+	AsfAccountTxnID:                 "AccountTxnID",
+	AsfNoFreeze:                     "NoFreeze",
+	AsfGlobalFreeze:                 "GlobalFreeze",
+	AsfDefaultRipple:                "DefaultRipple",
+	AsfDepositAuth:                  "DepositAuth",
+	AsfAuthorizedNFTokenMinter:      "AuthorizedNFTokenMinter",
+	AsfDisallowIncomingNFTokenOffer: "DisallowIncomingNFTokenOffer",
+	AsfDisallowIncomingCheck:        "DisallowIncomingCheck",
+	AsfDisallowIncomingPayChan:      "DisallowIncomingPayChan",
+	AsfDisallowIncomingTrustline:    "DisallowIncomingTrustline",
+	AsfAllowTrustLineClawback:       "AllowTrustLineClawback",
+}
+
+type txFlagName struct {
 	Flag TransactionFlag
 	Name string
-}{
+}
+
+var txFlagNames = map[TransactionType][]txFlagName{
 	PAYMENT: {
 		{TxNoDirectRipple, "NoDirectRipple"},
 		{TxPartialPayment, "PartialPayment"},
@@ -67,15 +135,10 @@ var txFlagNames = map[TransactionType][]struct {
 		{TxCircle, "Circle"},
 	},
 	ACCOUNT_SET: {
-		{TxSetRequireDest, "SetRequireDest"},
-		{TxSetRequireAuth, "SetRequireAuth"},
-		{TxSetDisallowXRP, "SetDisallowXRP"},
-		{TxSetDisableMaster, "SetDisableMaster"},
-		{TxNoFreeze, "NoFreeze"},
-		{TxGlobalFreeze, "GlobalFreeze"},
 		{TxRequireDestTag, "RequireDestTag"},
 		{TxOptionalDestTag, "OptionalDestTag"},
 		{TxRequireAuth, "RequireAuth"},
+		{TxOptionalAuth, "OptionalAuth"},
 		{TxDisallowXRP, "DisallowXRP"},
 		{TxAllowXRP, "AllowXRP"},
 	},
@@ -91,9 +154,65 @@ var txFlagNames = map[TransactionType][]struct {
 		{TxClearNoRipple, "ClearNoRipple"},
 		{TxSetFreeze, "SetFreeze"},
 		{TxClearFreeze, "ClearFreeze"},
+		{TxSetDeepFreeze, "SetDeepFreeze"},
+		{TxClearDeepFreeze, "ClearDeepFreeze"},
+	},
+	AMENDMENT: {
+		{TxGotMajority, "GotMajority"},
+		{TxLostMajority, "LostMajority"},
+	},
+	PAYCHAN_CLAIM: {
+		{TxRenew, "Renew"},
+		{TxClose, "Close"},
+	},
+	NFTOKEN_MINT: {
+		{TxBurnable, "Burnable"},
+		{TxOnlyXRP, "OnlyXRP"},
+		{TxTrustLine, "TrustLine"},
+		{TxTransferable, "Transferable"},
+		{TxMutable, "Mutable"},
+	},
+	NFTOKEN_CREATE_OFFER: {
+		{TxSellNFToken, "SellNFToken"},
+	},
+	AMM_DEPOSIT: {
+		{TxLPToken, "LPToken"},
+		{TxSingleAsset, "SingleAsset"},
+		{TxTwoAsset, "TwoAsset"},
+		{TxOneAssetLPToken, "OneAssetLPToken"},
+		{TxLimitLPToken, "LimitLPToken"},
+		{TxTwoAssetIfEmpty, "TwoAssetIfEmpty"},
+	},
+	AMM_WITHDRAW: {
+		{TxLPToken, "LPToken"},
+		{TxWithdrawAll, "WithdrawAll"},
+		{TxOneAssetWithdrawAll, "OneAssetWithdrawAll"},
+		{TxSingleAsset, "SingleAsset"},
+		{TxTwoAsset, "TwoAsset"},
+		{TxOneAssetLPToken, "OneAssetLPToken"},
+		{TxLimitLPToken, "LimitLPToken"},
+	},
+}
+
+// txFlagAliases are rippled's names, without the tf prefix, where they
+// differ from those of txFlagNames
+var txFlagAliases = map[TransactionType][]txFlagName{
+	PAYMENT: {
+		{TxNoDirectRipple, "NoRippleDirect"},
+	},
+	TRUST_SET: {
+		{TxSetAuth, "SetfAuth"},
 	},
 }
 
+// universalFlagNames are the flags valid for every transaction type
+var universalFlagNames = []txFlagName{
+	{TxCanonicalSignature, "CanonicalSignature"},
+	{TxCanonicalSignature, "FullyCanonicalSig"},
+	{TxInnerBatch, "InnerBatch"},
+	{TxInnerBatch, "InnerBatchTxn"},
+}
+
 // Ledger entry flags
 const (
 	// AccountRoot flags
@@ -105,20 +224,31 @@ const (
 	LsNoFreeze       LedgerEntryFlag = 0x00200000
 	LsGlobalFreeze   LedgerEntryFlag = 0x00400000
 	LsDefaultRipple  LedgerEntryFlag = 0x00800000
+	LsDepositAuth    LedgerEntryFlag = 0x01000000
+	LsAMM            LedgerEntryFlag = 0x02000000
+
+	LsDisallowIncomingNFTokenOffer LedgerEntryFlag = 0x04000000
+	LsDisallowIncomingCheck        LedgerEntryFlag = 0x08000000
+	LsDisallowIncomingPayChan      LedgerEntryFlag = 0x10000000
+	LsDisallowIncomingTrustline    LedgerEntryFlag = 0x20000000
+	LsAllowTrustLineClawback       LedgerEntryFlag = 0x80000000
 
 	// Offer flags
 	LsPassive LedgerEntryFlag = 0x00010000
 	LsSell    LedgerEntryFlag = 0x00020000
 
 	// RippleState flags
-	LsLowReserve   LedgerEntryFlag = 0x00010000
-	LsHighReserve  LedgerEntryFlag = 0x00020000
-	LsLowAuth      LedgerEntryFlag = 0x00040000
-	LsHighAuth     LedgerEntryFlag = 0x00080000
-	LsLowNoRipple  LedgerEntryFlag = 0x00100000
-	LsHighNoRipple LedgerEntryFlag = 0x00200000
-	LsLowFreeze    LedgerEntryFlag = 0x00400000
-	LsHighFreeze   LedgerEntryFlag = 0x00800000
+	LsLowReserve     LedgerEntryFlag = 0x00010000
+	LsHighReserve    LedgerEntryFlag = 0x00020000
+	LsLowAuth        LedgerEntryFlag = 0x00040000
+	LsHighAuth       LedgerEntryFlag = 0x00080000
+	LsLowNoRipple    LedgerEntryFlag = 0x00100000
+	LsHighNoRipple   LedgerEntryFlag = 0x00200000
+	LsLowFreeze      LedgerEntryFlag = 0x00400000
+	LsHighFreeze     LedgerEntryFlag = 0x00800000
+	LsAMMNode        LedgerEntryFlag = 0x01000000
+	LsLowDeepFreeze  LedgerEntryFlag = 0x02000000
+	LsHighDeepFreeze LedgerEntryFlag = 0x04000000
 
 	// SignerList flags
 	LsOneOwnerCount LedgerEntryFlag = 0x00010000
@@ -142,6 +272,15 @@ var leFlagNames = map[LedgerEntryType][]struct {
 		{LsDisallowXRP, "DisallowXRP"},
 		{LsDisableMaster, "DisableMaster"},
 		{LsNoFreeze, "NoFreeze"},
+		{LsGlobalFreeze, "GlobalFreeze"},
+		{LsDefaultRipple, "DefaultRipple"},
+		{LsDepositAuth, "DepositAuth"},
+		{LsAMM, "AMM"},
+		{LsDisallowIncomingNFTokenOffer, "DisallowIncomingNFTokenOffer"},
+		{LsDisallowIncomingCheck, "DisallowIncomingCheck"},
+		{LsDisallowIncomingPayChan, "DisallowIncomingPayChan"},
+		{LsDisallowIncomingTrustline, "DisallowIncomingTrustline"},
+		{LsAllowTrustLineClawback, "AllowTrustLineClawback"},
 	},
 	OFFER: {
 		{LsPassive, "Passive"},
@@ -156,6 +295,9 @@ var leFlagNames = map[LedgerEntryType][]struct {
 		{LsHighNoRipple, "HighNoRipple"},
 		{LsLowFreeze, "LowFreeze"},
 		{LsHighFreeze, "HighFreeze"},
+		{LsAMMNode, "AMMNode"},
+		{LsLowDeepFreeze, "LowDeepFreeze"},
+		{LsHighDeepFreeze, "HighDeepFreeze"},
 	},
 	SIGNER_LIST: {
 		{LsOneOwnerCount, "OneOwnerCount"},
@@ -177,6 +319,16 @@ func (f LedgerEntryFlag) String() string {
 	return fmt.Sprintf("%08X", uint32(f))
 }
 
+func (f AccountSetFlag) String() string {
+	if name, ok := accountSetFlagNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown(%d)", uint32(f))
+}
+
+// Explain returns the names of the flags set in f for the type of tx. The
+// SetFlag and ClearFlag of an AccountSet are included, prefixed with Set
+// and Clear.
 func (f TransactionFlag) Explain(tx Transaction) []string {
 	var flags []string
 	if f&TxCanonicalSignature > 0 {
@@ -187,6 +339,14 @@ func (f TransactionFlag) Explain(tx Transaction) []string {
 			flags = append(flags, n.Name)
 		}
 	}
+	if as, ok := tx.(*AccountSet); ok {
+		if as.SetFlag != nil {
+			flags = append(flags, "Set"+AccountSetFlag(*as.SetFlag).String())
+		}
+		if as.ClearFlag != nil {
+			flags = append(flags, "Clear"+AccountSetFlag(*as.ClearFlag).String())
+		}
+	}
 	return flags
 }
 
@@ -199,3 +359,57 @@ func (f LedgerEntryFlag) Explain(le LedgerEntry) []string {
 	}
 	return flags
 }
+
+// flagName matches a flag name ignoring case and rippled's prefix
+func flagName(name, prefix, flag string) bool {
+	return strings.EqualFold(name, flag) || strings.EqualFold(name, prefix+flag)
+}
+
+// NewTransactionFlag returns the flags of a transaction of type typ with
+// the supplied names set, such as "Sell" or "tfSell" for an OfferCreate.
+// rippled's names are accepted where they differ, such as "tfNoRippleDirect"
+// for NoDirectRipple.
+func NewTransactionFlag(typ TransactionType, names ...string) (TransactionFlag, error) {
+	var f TransactionFlag
+outer:
+	for _, name := range names {
+		for _, table := range [][]txFlagName{universalFlagNames, txFlagNames[typ], txFlagAliases[typ]} {
+			for _, n := range table {
+				if flagName(name, "tf", n.Name) {
+					f |= n.Flag
+					continue outer
+				}
+			}
+		}
+		return 0, fmt.Errorf("Unknown %s flag: %s", typ, name)
+	}
+	return f, nil
+}
+
+// NewLedgerEntryFlag returns the flags of a ledger entry of type typ with
+// the supplied names set, such as "DefaultRipple" or "lsfDefaultRipple".
+func NewLedgerEntryFlag(typ LedgerEntryType, names ...string) (LedgerEntryFlag, error) {
+	var f LedgerEntryFlag
+outer:
+	for _, name := range names {
+		for _, n := range leFlagNames[typ] {
+			if flagName(name, "lsf", n.Name) {
+				f |= n.Flag
+				continue outer
+			}
+		}
+		return 0, fmt.Errorf("Unknown %s flag: %s", typ, name)
+	}
+	return f, nil
+}
+
+// NewAccountSetFlag returns the SetFlag or ClearFlag value with the supplied
+// name, such as "DefaultRipple" or "asfDefaultRipple".
+func NewAccountSetFlag(name string) (AccountSetFlag, error) {
+	for f, n := range accountSetFlagNames {
+		if flagName(name, "asf", n) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown AccountSet flag: %s", name)
+}
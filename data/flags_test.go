@@ -0,0 +1,128 @@
+package data
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type FlagsSuite struct{}
+
+var _ = Suite(&FlagsSuite{})
+
+func (s *FlagsSuite) TestExplain(c *C) {
+	mint := &NFTokenMint{TxBase: TxBase{TransactionType: NFTOKEN_MINT}}
+	c.Check((TxBurnable | TxTransferable).Explain(mint), DeepEquals, []string{"Burnable", "Transferable"})
+
+	withdraw := &AMMWithdraw{TxBase: TxBase{TransactionType: AMM_WITHDRAW}}
+	c.Check(TxWithdrawAll.Explain(withdraw), DeepEquals, []string{"WithdrawAll"})
+
+	set, clear := uint32(AsfDefaultRipple), uint32(AsfRequireAuth)
+	accountSet := &AccountSet{TxBase: TxBase{TransactionType: ACCOUNT_SET}, SetFlag: &set, ClearFlag: &clear}
+	c.Check(TxCanonicalSignature.Explain(accountSet), DeepEquals, []string{"CanonicalSignature", "SetDefaultRipple", "ClearRequireAuth"})
+
+	c.Check(LsDepositAuth.Explain(&AccountRoot{leBase: leBase{LedgerEntryType: ACCOUNT_ROOT}}), DeepEquals, []string{"DepositAuth"})
+}
+
+func (s *FlagsSuite) TestNewFlags(c *C) {
+	f, err := NewTransactionFlag(OFFER_CREATE, "Sell", "tfImmediateOrCancel", "fullyCanonicalSig")
+	c.Assert(err, IsNil)
+	c.Check(f, Equals, TxSell|TxImmediateOrCancel|TxCanonicalSignature)
+
+	f, err = NewTransactionFlag(AMM_DEPOSIT, "tfLPToken")
+	c.Assert(err, IsNil)
+	c.Check(f, Equals, TxLPToken)
+
+	_, err = NewTransactionFlag(PAYMENT, "Sell")
+	c.Check(err, ErrorMatches, "Unknown Payment flag: Sell")
+
+	le, err := NewLedgerEntryFlag(RIPPLE_STATE, "lsfLowNoRipple", "HighFreeze")
+	c.Assert(err, IsNil)
+	c.Check(le, Equals, LsLowNoRipple|LsHighFreeze)
+
+	asf, err := NewAccountSetFlag("asfDefaultRipple")
+	c.Assert(err, IsNil)
+	c.Check(asf, Equals, AsfDefaultRipple)
+	_, err = NewAccountSetFlag("Sell")
+	c.Check(err, NotNil)
+}
+
+// The names of rippled's TxFlags.h for each transaction type
+func (s *FlagsSuite) TestRippledNames(c *C) {
+	for _, test := range []struct {
+		typ   TransactionType
+		names map[string]TransactionFlag
+	}{
+		{PAYMENT, map[string]TransactionFlag{
+			"tfNoRippleDirect": TxNoDirectRipple,
+			"tfPartialPayment": TxPartialPayment,
+			"tfLimitQuality":   TxLimitQuality,
+		}},
+		{ACCOUNT_SET, map[string]TransactionFlag{
+			"tfRequireDestTag":  TxRequireDestTag,
+			"tfOptionalDestTag": TxOptionalDestTag,
+			"tfRequireAuth":     TxRequireAuth,
+			"tfOptionalAuth":    TxOptionalAuth,
+			"tfDisallowXRP":     TxDisallowXRP,
+			"tfAllowXRP":        TxAllowXRP,
+		}},
+		{OFFER_CREATE, map[string]TransactionFlag{
+			"tfPassive":           TxPassive,
+			"tfImmediateOrCancel": TxImmediateOrCancel,
+			"tfFillOrKill":        TxFillOrKill,
+			"tfSell":              TxSell,
+		}},
+		{TRUST_SET, map[string]TransactionFlag{
+			"tfSetfAuth":        TxSetAuth,
+			"tfSetNoRipple":     TxSetNoRipple,
+			"tfClearNoRipple":   TxClearNoRipple,
+			"tfSetFreeze":       TxSetFreeze,
+			"tfClearFreeze":     TxClearFreeze,
+			"tfSetDeepFreeze":   TxSetDeepFreeze,
+			"tfClearDeepFreeze": TxClearDeepFreeze,
+		}},
+		{AMENDMENT, map[string]TransactionFlag{
+			"tfGotMajority":  TxGotMajority,
+			"tfLostMajority": TxLostMajority,
+		}},
+		{PAYCHAN_CLAIM, map[string]TransactionFlag{
+			"tfRenew": TxRenew,
+			"tfClose": TxClose,
+		}},
+		{NFTOKEN_MINT, map[string]TransactionFlag{
+			"tfBurnable":     TxBurnable,
+			"tfOnlyXRP":      TxOnlyXRP,
+			"tfTrustLine":    TxTrustLine,
+			"tfTransferable": TxTransferable,
+			"tfMutable":      TxMutable,
+		}},
+		{NFTOKEN_CREATE_OFFER, map[string]TransactionFlag{
+			"tfSellNFToken": TxSellNFToken,
+		}},
+		{AMM_DEPOSIT, map[string]TransactionFlag{
+			"tfLPToken":         TxLPToken,
+			"tfSingleAsset":     TxSingleAsset,
+			"tfTwoAsset":        TxTwoAsset,
+			"tfOneAssetLPToken": TxOneAssetLPToken,
+			"tfLimitLPToken":    TxLimitLPToken,
+			"tfTwoAssetIfEmpty": TxTwoAssetIfEmpty,
+		}},
+		{AMM_WITHDRAW, map[string]TransactionFlag{
+			"tfLPToken":             TxLPToken,
+			"tfWithdrawAll":         TxWithdrawAll,
+			"tfOneAssetWithdrawAll": TxOneAssetWithdrawAll,
+			"tfSingleAsset":         TxSingleAsset,
+			"tfTwoAsset":            TxTwoAsset,
+			"tfOneAssetLPToken":     TxOneAssetLPToken,
+			"tfLimitLPToken":        TxLimitLPToken,
+		}},
+	} {
+		test.names["tfFullyCanonicalSig"] = TxCanonicalSignature
+		test.names["tfInnerBatchTxn"] = TxInnerBatch
+		for name, flag := range test.names {
+			f, err := NewTransactionFlag(test.typ, name)
+			c.Check(err, IsNil, Commentf("%s %s", test.typ, name))
+			c.Check(f, Equals, flag, Commentf("%s %s", test.typ, name))
+		}
+	}
+	_, err := NewTransactionFlag(OFFER_CREATE, "tfNoRippleDirect")
+	c.Check(err, ErrorMatches, "Unknown OfferCreate flag: tfNoRippleDirect")
+}
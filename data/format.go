@@ -23,6 +23,9 @@ const (
 	HP_TRANSACTION_MULTISIGN HashPrefix = 0x534D5400 // 'SMT' inner transaction to multi-sign
 	HP_VALIDATION            HashPrefix = 0x56414C00 // 'VAL' validation for signing
 	HP_PROPOSAL              HashPrefix = 0x50525000 // 'PRP' proposal for signing
+	HP_MANIFEST              HashPrefix = 0x4D414E00 // 'MAN' manifest for signing
+	HP_PAYMENT_CHANNEL_CLAIM HashPrefix = 0x434C4D00 // 'CLM' payment channel claim for signing
+	HP_MESSAGE               HashPrefix = 0x4D534700 // 'MSG' message for signing, not used by rippled
 
 	// Node Types
 	NT_UNKNOWN          NodeType = 0
@@ -54,6 +57,9 @@ const (
 	NS_DEPOSIT_PREAUTH LedgerNamespace = 'p'
 	NS_NEGATIVE_UNL    LedgerNamespace = 'N'
 	NS_AMM             LedgerNamespace = 'A'
+	NS_NFTOKEN_OFFER   LedgerNamespace = 'q'
+	NS_NFTOKEN_BUYS    LedgerNamespace = 'h' // Directory of buy offers for an NFToken
+	NS_NFTOKEN_SELLS   LedgerNamespace = 'i' // Directory of sell offers for an NFToken
 )
 
 var nodeTypes = [...]string{
@@ -167,6 +167,13 @@ func (p PublicKey) NodePublicKey() string {
 	return hash.String()
 }
 
+// Account returns the account id derived from the public key
+func (p PublicKey) Account() Account {
+	var account Account
+	copy(account[:], crypto.Sha256RipeMD160(p[:]))
+	return account
+}
+
 func (p PublicKey) String() string {
 	b, _ := p.MarshalText()
 	return string(b)
@@ -194,16 +201,34 @@ func NewAccountFromAddress(s string) (*Account, error) {
 	return &account, nil
 }
 
+// Expects an X-address, returns the account, the optional tag and whether
+// the address is for testnet
+func NewAccountFromXAddress(s string) (*Account, *uint32, bool, error) {
+	id, tag, testnet, err := crypto.DecodeXAddress(s)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	var account Account
+	copy(account[:], id)
+	return &account, tag, testnet, nil
+}
+
+// XAddress returns the X-address of a with an optional tag
+func (a Account) XAddress(tag *uint32, testnet bool) (string, error) {
+	return crypto.EncodeXAddress(a[:], tag, testnet)
+}
+
 func (a Account) Hash() (crypto.Hash, error) {
 	return crypto.NewAccountId(a[:])
 }
 
 func (a Account) String() string {
-	address, err := a.Hash()
+	var buf [35]byte
+	address, err := crypto.AppendRippleHash(buf[:0], crypto.RIPPLE_ACCOUNT_ID, a[:])
 	if err != nil {
 		return fmt.Sprintf("Bad Address: %s", b2h(a[:]))
 	}
-	return address.String()
+	return string(address)
 }
 
 func (a Account) IsZero() bool {
@@ -265,21 +290,55 @@ func (r *RegularKey) Bytes() []byte {
 	return []byte(nil)
 }
 
-// Expects address in base58 form
-func NewSeedFromAddress(s string) (*Seed, error) {
+// Expects address in base58 form or as XLS-12 secret numbers. Seeds
+// starting with sEd are for Ed25519 keys, all others for ECDSA keys.
+func NewSeedFromAddress(s string) (*Seed, KeyType, error) {
+	if crypto.IsSecretNumbers(s) {
+		seed, err := NewSeedFromSecretNumbers(s)
+		return seed, ECDSA, err
+	}
 	hash, err := crypto.NewRippleHashCheck(s, crypto.RIPPLE_FAMILY_SEED)
 	if err != nil {
-		return nil, err
+		return nil, ECDSA, err
 	}
 	var seed Seed
 	copy(seed[:], hash.Payload())
+	if crypto.IsEd25519FamilySeed(hash) {
+		return &seed, Ed25519, nil
+	}
+	return &seed, ECDSA, nil
+}
+
+// NewSeedFromSecretNumbers expects eight blocks of six digits
+func NewSeedFromSecretNumbers(s string) (*Seed, error) {
+	b, err := crypto.DecodeSecretNumbers(s)
+	if err != nil {
+		return nil, err
+	}
+	var seed Seed
+	copy(seed[:], b)
 	return &seed, nil
 }
 
+// SecretNumbers returns the seed as XLS-12 secret numbers
+func (s Seed) SecretNumbers() string {
+	numbers, _ := crypto.EncodeSecretNumbers(s[:])
+	return numbers
+}
+
 func (s Seed) Hash() (crypto.Hash, error) {
 	return crypto.NewFamilySeed(s[:])
 }
 
+// HashWithKeyType returns the seed encoded for keyType, which starts with
+// sEd for Ed25519
+func (s Seed) HashWithKeyType(keyType KeyType) (crypto.Hash, error) {
+	if keyType == Ed25519 {
+		return crypto.NewEd25519FamilySeed(s[:])
+	}
+	return s.Hash()
+}
+
 func (s Seed) String() string {
 	address, err := s.Hash()
 	if err != nil {
@@ -312,6 +371,38 @@ func (s *Seed) Key(keyType KeyType) crypto.Key {
 	return key
 }
 
+// TypedSeed is a Seed with the type of key derived from it. As text it is
+// the seed's address, which starts with sEd for Ed25519.
+type TypedSeed struct {
+	Seed    Seed
+	KeyType KeyType
+}
+
+// NewTypedSeed expects an address or secret numbers as NewSeedFromAddress
+func NewTypedSeed(s string) (*TypedSeed, error) {
+	seed, keyType, err := NewSeedFromAddress(s)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedSeed{Seed: *seed, KeyType: keyType}, nil
+}
+
+func (t TypedSeed) Hash() (crypto.Hash, error) {
+	return t.Seed.HashWithKeyType(t.KeyType)
+}
+
+func (t TypedSeed) String() string {
+	address, err := t.Hash()
+	if err != nil {
+		return fmt.Sprintf("Bad Address: %s", b2h(t.Seed[:]))
+	}
+	return address.String()
+}
+
+func (t *TypedSeed) Key() crypto.Key {
+	return t.Seed.Key(t.KeyType)
+}
+
 func (s *Seed) AccountId(keyType KeyType, sequence *uint32) Account {
 	var account Account
 	copy(account[:], s.Key(keyType).Id(sequence))
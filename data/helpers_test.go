@@ -0,0 +1,33 @@
+package data
+
+// Accounts, amounts and transactions shared by the suites of the package
+
+const (
+	testIssuer      = "rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL"
+	testAccount     = "r3ADD8kXSUKHd6zTCKfnKT3zV9EZHjzp1S"
+	testDestination = "rJMNfiJTwXHcMdB4SpxMgL3mvV4xUVHDnd"
+)
+
+func accountCheck(address string) Account {
+	account, err := NewAccountFromAddress(address)
+	if err != nil {
+		panic(err)
+	}
+	return *account
+}
+
+// usdCheck returns v USD issued by testIssuer
+func usdCheck(v string) *Amount {
+	return amountCheck(v + "/USD/" + testIssuer)
+}
+
+// txBaseCheck returns the fields common to a valid transaction of typ
+// from testAccount
+func txBaseCheck(typ TransactionType) TxBase {
+	return TxBase{
+		TransactionType: typ,
+		Account:         accountCheck(testAccount),
+		Sequence:        1,
+		Fee:             *amountCheck("10/XRP").Value,
+	}
+}
@@ -3,6 +3,7 @@ package data
 import (
 	"bytes"
 	"crypto/sha512"
+	"encoding/binary"
 	"fmt"
 	"math"
 )
@@ -25,6 +26,10 @@ func (i *NodeIndex) Next() *NodeIndex {
 	return &next
 }
 
+// LedgerIndex computes the index of a LedgerEntry from its fields. Some
+// entries do not hold all the fields their index is built from, for those
+// an error is returned and the index must be computed with the matching
+// Get*Index function.
 func LedgerIndex(le LedgerEntry) (*Hash256, error) {
 	switch v := le.(type) {
 	case *AccountRoot:
@@ -38,11 +43,43 @@ func LedgerIndex(le LedgerEntry) (*Hash256, error) {
 	case *Directory:
 		return GetDirectoryNodeIndex(*v.RootIndex, v.IndexPrevious.Next())
 	case *FeeSettings:
-		return buildIndex([]interface{}{NS_FEE})
+		return GetFeeIndex()
 	case *Amendments:
-		return buildIndex([]interface{}{NS_AMENDMENT})
+		return GetAmendmentsIndex()
+	case *NegativeUNL:
+		return GetNegativeUNLIndex()
+	case *Check:
+		if v.Account == nil || v.Sequence == nil {
+			return nil, fmt.Errorf("Check index needs Account and Sequence")
+		}
+		return GetCheckIndex(*v.Account, *v.Sequence)
+	case *Ticket:
+		if v.Account == nil || v.TicketSequence == nil {
+			return nil, fmt.Errorf("Ticket index needs Account and TicketSequence")
+		}
+		return GetTicketIndex(*v.Account, *v.TicketSequence)
+	case *DepositPreAuth:
+		if v.Account == nil || v.Authorize == nil {
+			return nil, fmt.Errorf("DepositPreAuth index needs Account and Authorize")
+		}
+		return GetDepositPreAuthIndex(*v.Account, *v.Authorize)
+	case *AMM:
+		if v.Asset == nil || v.Asset2 == nil {
+			return nil, fmt.Errorf("AMM index needs Asset and Asset2")
+		}
+		return GetAMMIndex(*v.Asset, *v.Asset2)
+	case *Escrow:
+		return nil, fmt.Errorf("Escrow index needs the EscrowCreate sequence: use GetEscrowIndex")
+	case *PayChannel:
+		return nil, fmt.Errorf("PayChannel index needs the PaymentChannelCreate sequence: use GetPayChannelIndex")
+	case *SignerList:
+		return nil, fmt.Errorf("SignerList index needs the owner: use GetSignerListIndex")
+	case *NFTokenOffer:
+		return nil, fmt.Errorf("NFTokenOffer index needs the NFTokenCreateOffer sequence: use GetNFTokenOfferIndex")
+	case *NFTokenPage:
+		return nil, fmt.Errorf("NFTokenPage index needs the owner: use GetNFTokenPageIndex")
 	default:
-		return nil, fmt.Errorf("Unknown LedgerEntry")
+		return nil, fmt.Errorf("Unknown LedgerEntry: %s", le.GetType())
 	}
 }
 
@@ -72,15 +109,23 @@ func GetOwnerDirectoryIndex(account Account) (*Hash256, error) {
 	return buildIndex([]interface{}{NS_OWNER_DIRECTORY, account.Bytes()})
 }
 
-func GetBookIndex(paysCurrency, getsCurrency Hash160, paysIssuer, getsIssuer Hash160) (*Hash256, error) {
-	//TODO: change types to Currency and Account
-	index, err := buildIndex([]interface{}{NS_BOOK_DIRECTORY, paysCurrency.Bytes(), getsCurrency.Bytes(), paysCurrency.Bytes(), getsCurrency.Bytes()})
+// GetBookIndex returns the first directory index of the order book in which
+// the taker pays one currency/issuer and gets the other. The low 64 bits,
+// which hold the quality of the book's directories, are zero.
+func GetBookIndex(paysCurrency, getsCurrency Currency, paysIssuer, getsIssuer Account) (*Hash256, error) {
+	index, err := buildIndex([]interface{}{NS_BOOK_DIRECTORY, paysCurrency.Bytes(), getsCurrency.Bytes(), paysIssuer.Bytes(), getsIssuer.Bytes()})
 	if err != nil {
 		return nil, err
 	}
-	var zero [8]byte
-	copy(index[24:], zero[:])
-	return index, nil
+	return GetQualityIndex(*index, 0), nil
+}
+
+// GetQualityIndex returns the index of the directory holding the offers of
+// a book with the supplied quality. base is the index of any of the book's
+// directories.
+func GetQualityIndex(base Hash256, quality uint64) *Hash256 {
+	binary.BigEndian.PutUint64(base[24:], quality)
+	return &base
 }
 
 func GetFeeIndex() (*Hash256, error) {
@@ -99,6 +144,91 @@ func GetPreviousLedgerHashIndex(sequence uint32) (*Hash256, error) {
 	return buildIndex([]interface{}{NS_SKIP_LIST, sequence >> 16})
 }
 
+func GetNegativeUNLIndex() (*Hash256, error) {
+	return buildIndex([]interface{}{NS_NEGATIVE_UNL})
+}
+
+func GetEscrowIndex(account Account, sequence uint32) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_SUSPAY, account.Bytes(), sequence})
+}
+
+func GetCheckIndex(account Account, sequence uint32) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_CHECK, account.Bytes(), sequence})
+}
+
+func GetTicketIndex(account Account, ticketSequence uint32) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_TICKET, account.Bytes(), ticketSequence})
+}
+
+func GetPayChannelIndex(account, destination Account, sequence uint32) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_XRPU_CHANNEL, account.Bytes(), destination.Bytes(), sequence})
+}
+
+func GetDepositPreAuthIndex(account, authorized Account) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_DEPOSIT_PREAUTH, account.Bytes(), authorized.Bytes()})
+}
+
+// GetSignerListIndex returns the index of an account's SignerList.
+// Only a SignerListID of zero is in use.
+func GetSignerListIndex(account Account) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_SIGNER_LIST, account.Bytes(), uint32(0)})
+}
+
+func GetNFTokenOfferIndex(owner Account, sequence uint32) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_NFTOKEN_OFFER, owner.Bytes(), sequence})
+}
+
+func GetNFTokenBuyOffersIndex(nfTokenID Hash256) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_NFTOKEN_BUYS, nfTokenID})
+}
+
+func GetNFTokenSellOffersIndex(nfTokenID Hash256) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_NFTOKEN_SELLS, nfTokenID})
+}
+
+// GetNFTokenPageIndex returns the index of the NFTokenPage of owner which
+// would hold nfTokenID, if such a page exists. Page indexes are not hashed,
+// they are the owner's account followed by the low 96 bits of the largest
+// NFTokenID a page can hold.
+func GetNFTokenPageIndex(owner Account, nfTokenID Hash256) *Hash256 {
+	var index Hash256
+	copy(index[:20], owner[:])
+	copy(index[20:], nfTokenID[20:])
+	return &index
+}
+
+// GetNFTokenPageMinIndex returns the lowest possible NFTokenPage index for owner
+func GetNFTokenPageMinIndex(owner Account) *Hash256 {
+	return GetNFTokenPageIndex(owner, zero256)
+}
+
+// GetNFTokenPageMaxIndex returns the highest possible NFTokenPage index for
+// owner, which is the index of the last page of owner's NFTokens.
+func GetNFTokenPageMaxIndex(owner Account) *Hash256 {
+	var max Hash256
+	for i := range max {
+		max[i] = 0xFF
+	}
+	return GetNFTokenPageIndex(owner, max)
+}
+
+// GetAMMIndex returns the index of the AMM for a pair of assets. The order
+// of the assets does not matter.
+func GetAMMIndex(asset, asset2 Issue) (*Hash256, error) {
+	if compareIssues(asset, asset2) > 0 {
+		asset, asset2 = asset2, asset
+	}
+	return buildIndex([]interface{}{NS_AMM, asset.Issuer.Bytes(), asset.Currency.Bytes(), asset2.Issuer.Bytes(), asset2.Currency.Bytes()})
+}
+
+// compareIssues orders by currency and then issuer
+func compareIssues(a, b Issue) int {
+	if c := bytes.Compare(a.Currency.Bytes(), b.Currency.Bytes()); c != 0 || a.Currency.IsNative() {
+		return c
+	}
+	return a.Issuer.Compare(b.Issuer)
+}
+
 func buildIndex(items []interface{}) (*Hash256, error) {
 	index := sha512.New()
 	for _, item := range items {
@@ -0,0 +1,108 @@
+package data
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type IndexSuite struct{}
+
+var _ = Suite(&IndexSuite{})
+
+func (s *IndexSuite) TestLedgerIndexes(c *C) {
+	ledger := loadLedger(c, "testdata/ledger_6000000.json")
+	var checked int
+	for _, le := range ledger.AccountState {
+		switch le.(type) {
+		case *AccountRoot, *RippleState, *Offer, *FeeSettings, *Amendments:
+		default:
+			continue
+		}
+		index, err := LedgerIndex(le)
+		c.Assert(err, IsNil)
+		c.Check(index.String(), Equals, le.GetLedgerIndex().String(), Commentf("%s", le.GetType()))
+		checked++
+	}
+	c.Check(checked > 100, Equals, true)
+}
+
+func (s *IndexSuite) TestBookIndexes(c *C) {
+	ledger := loadLedger(c, "testdata/ledger_6000000.json")
+	var checked int
+	for _, le := range ledger.AccountState {
+		dir, ok := le.(*Directory)
+		if !ok || dir.TakerPaysCurrency == nil || dir.IndexPrevious != nil {
+			continue
+		}
+		base, err := GetBookIndex(*dir.TakerPaysCurrency.Currency(), *dir.TakerGetsCurrency.Currency(), *dir.TakerPaysIssuer.Account(), *dir.TakerGetsIssuer.Account())
+		c.Assert(err, IsNil)
+		c.Check(GetQualityIndex(*base, uint64(*dir.ExchangeRate)).String(), Equals, dir.GetLedgerIndex().String())
+		checked++
+	}
+	c.Check(checked > 0, Equals, true)
+}
+
+// Indexes published in xrpl.js's hash tests and in the examples of the XRPL
+// documentation's ledger object reference. There are none to hand for
+// Ticket, NFTokenOffer or AMM indexes.
+func (s *IndexSuite) TestKnownIndexes(c *C) {
+	account := func(address string) Account {
+		a, err := NewAccountFromAddress(address)
+		c.Assert(err, IsNil)
+		return *a
+	}
+	index := func(h *Hash256, err error) string {
+		c.Assert(err, IsNil)
+		return h.String()
+	}
+	for _, test := range []struct {
+		index    string
+		expected string
+	}{
+		{index(GetOfferIndex(account("r32UufnaCGL82HubijgJGDmdE5hac7ZvLw"), 137)), "03F0AED09DEEE74CEF85CD57A0429D6113507CF759C597BABB4ADB752F734CE3"},
+		{index(GetEscrowIndex(account("rDx69ebzbowuqztksVDmZXjizTd12BVr4x"), 84)), "61E8E8ED53FA2CEBE192B23897071E9A75217BF5A410E9CB5B45AAB7AECA567A"},
+		{index(GetEscrowIndex(account("rf1BiGeXwwQoi8Z2ueFYTEXSwuJYfV2Jpn"), 366)), "DC5F3851D8A1AB622F957761E5963BC5BD439D5C24AC6AD7AC4523F0640244AC"},
+		{index(GetPayChannelIndex(account("rDx69ebzbowuqztksVDmZXjizTd12BVr4x"), account("rLFtVprxUEfsH54eCWKsZrEQzMDsx1wqso"), 82)), "E35708503B3C3143FB522D749AAFCC296E8060F0FB371A9A56FAE0B1ED127366"},
+		{index(GetCheckIndex(account("rUn84CUYbNjRoTQ6mSW7BVJPSVJNLb1QLo"), 2)), "49647F0D748DC3FE26BDACBC57F251AADEFFF391403EC9BF87C97F67E9977FB0"},
+		{index(GetDepositPreAuthIndex(account("rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8"), account("rEhxGqkqPPSxQ3P25J66ft5TwpzV14k2de"))), "4A255038CC3ADCC1A9C91509279B59908251728D0DAADB248FFE297D0F7E068C"},
+		{index(GetSignerListIndex(account("rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"))), "778365D5180F5DF3016817D1F318527AD7410D83F8636CF48C43E8AF72AB49BF"},
+	} {
+		c.Check(test.index, Equals, test.expected)
+	}
+}
+
+func (s *IndexSuite) TestAMMIndex(c *C) {
+	usd := amountCheck("1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL").Issue()
+	eur := amountCheck("1/EUR/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL").Issue()
+	xrp := Issue{}
+	for _, pair := range [][2]Issue{{usd, eur}, {usd, xrp}, {eur, xrp}} {
+		a, err := GetAMMIndex(pair[0], pair[1])
+		c.Assert(err, IsNil)
+		b, err := GetAMMIndex(pair[1], pair[0])
+		c.Assert(err, IsNil)
+		c.Check(*a, Equals, *b)
+	}
+	a, err := GetAMMIndex(usd, xrp)
+	c.Assert(err, IsNil)
+	b, err := GetAMMIndex(eur, xrp)
+	c.Assert(err, IsNil)
+	c.Check(*a, Not(Equals), *b)
+}
+
+func (s *IndexSuite) TestNFTokenPageIndex(c *C) {
+	owner := amountCheck("1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL").Issuer
+	id, err := NewHash256("000B013A95F14B0044F78A264E41713C64B5F89242540EE208C3098E00000D65")
+	c.Assert(err, IsNil)
+	index := GetNFTokenPageIndex(owner, *id)
+	c.Check(index[:20], DeepEquals, owner[:])
+	c.Check(index[20:], DeepEquals, id[20:])
+	min, max := GetNFTokenPageMinIndex(owner), GetNFTokenPageMaxIndex(owner)
+	c.Check(min.String() < index.String(), Equals, true)
+	c.Check(index.String() < max.String(), Equals, true)
+}
+
+func (s *IndexSuite) TestMissingFields(c *C) {
+	for _, le := range []LedgerEntry{&Escrow{}, &PayChannel{}, &SignerList{}, &NFTokenOffer{}, &NFTokenPage{}, &Check{}} {
+		_, err := LedgerIndex(le)
+		c.Check(err, NotNil)
+	}
+}
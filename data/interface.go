@@ -53,6 +53,7 @@ type Transaction interface {
 	GetTransactionType() TransactionType
 	GetBase() *TxBase
 	PathSet() PathSet
+	Validate() TransactionResult
 }
 
 type Wire interface {
@@ -1,15 +1,75 @@
 package data
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
 
+// Issue is a currency and its issuer, which is zero for XRP. Issues are
+// comparable and can be used as map keys.
 type Issue struct {
 	Currency Currency `json:"currency"`
 	Issuer   Account  `json:"issuer,omitempty"`
 }
 
+// NewIssue accepts XRP or currency/issuer, the string form of an Asset
+func NewIssue(s string) (*Issue, error) {
+	if s == "XRP" {
+		return &Issue{}, nil
+	}
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("Bad issue: %s", s)
+	}
+	currency, err := NewCurrency(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := currency.CheckIssued(); err != nil {
+		return nil, err
+	}
+	issuer, err := NewAccountFromAddress(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	return &Issue{Currency: currency, Issuer: *issuer}, nil
+}
+
+func (i Issue) IsNative() bool {
+	return i.Currency.IsNative()
+}
+
+// Matches returns true if amount is of the issue
+func (i Issue) Matches(amount *Amount) bool {
+	if i.IsNative() {
+		return amount.IsNative()
+	}
+	return !amount.IsNative() && i.Currency == amount.Currency && i.Issuer == amount.Issuer
+}
+
+// Asset returns the issue in string form
+func (i Issue) Asset() Asset {
+	if i.IsNative() {
+		return Asset{Currency: "XRP"}
+	}
+	return Asset{Currency: i.Currency.Machine(), Issuer: i.Issuer.String()}
+}
+
 func (i Issue) String() string {
 	if i.Currency.IsNative() {
 		return i.Currency.String()
 	}
 	return fmt.Sprintf("%s/%s", i.Currency, i.Issuer)
 }
+
+// The issuer is omitted for XRP
+func (i Issue) MarshalJSON() ([]byte, error) {
+	if i.IsNative() {
+		return json.Marshal(struct {
+			Currency Currency `json:"currency"`
+		}{i.Currency})
+	}
+	type issueJSON Issue
+	return json.Marshal(issueJSON(i))
+}
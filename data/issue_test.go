@@ -0,0 +1,80 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type IssueSuite struct{}
+
+var _ = Suite(&IssueSuite{})
+
+func (s *IssueSuite) TestNewIssue(c *C) {
+	xrp, err := NewIssue("XRP")
+	c.Assert(err, IsNil)
+	c.Check(xrp.IsNative(), Equals, true)
+	c.Check(xrp.Matches(amountCheck("1/XRP")), Equals, true)
+
+	usd, err := NewIssue("USD/" + testIssuer)
+	c.Assert(err, IsNil)
+	c.Check(*usd, Equals, usdCheck("1").Issue())
+	c.Check(usd.Matches(usdCheck("1")), Equals, true)
+	c.Check(usd.Matches(amountCheck("1/XRP")), Equals, false)
+	c.Check(usd.String(), Equals, "USD/"+testIssuer)
+	c.Check(usd.Asset(), Equals, Asset{Currency: "USD", Issuer: testIssuer})
+
+	asset, err := NewAsset("USD/" + testIssuer)
+	c.Assert(err, IsNil)
+	converted, err := asset.Issue()
+	c.Assert(err, IsNil)
+	c.Check(converted, DeepEquals, usd)
+
+	_, err = NewIssue("USD")
+	c.Check(err, ErrorMatches, "Bad issue: USD")
+	_, err = NewIssue("XRP/" + testIssuer)
+	c.Check(err, ErrorMatches, "XRP cannot be an issued currency")
+}
+
+func (s *IssueSuite) TestJSON(c *C) {
+	usd := usdCheck("1").Issue()
+	for _, test := range []struct {
+		issue    Issue
+		expected string
+	}{
+		{Issue{}, `{"currency":"XRP"}`},
+		{usd, `{"currency":"USD","issuer":"` + testIssuer + `"}`},
+	} {
+		b, err := json.Marshal(test.issue)
+		c.Assert(err, IsNil)
+		c.Check(string(b), Equals, test.expected)
+		var issue Issue
+		c.Assert(json.Unmarshal(b, &issue), IsNil)
+		c.Check(issue, Equals, test.issue)
+	}
+}
+
+func (s *IssueSuite) TestGetSequences(c *C) {
+	offers := AccountOfferSlice{
+		{Sequence: 3, TakerPays: *usdCheck("1"), TakerGets: *amountCheck("1/XRP")},
+		{Sequence: 2, TakerPays: *amountCheck("1/XRP"), TakerGets: *usdCheck("1")},
+		{Sequence: 1, TakerPays: *usdCheck("2"), TakerGets: *amountCheck("1/XRP")},
+	}
+	c.Check(offers.GetSequences(usdCheck("1").Issue(), Issue{}), DeepEquals, []uint32{3, 1})
+	c.Check(offers.GetSequences(Issue{}, usdCheck("1").Issue()), DeepEquals, []uint32{2})
+}
+
+func (s *IssueSuite) TestAMMEncoding(c *C) {
+	vote := &AMMVote{
+		TxBase:     txBaseCheck(AMM_VOTE),
+		Asset2:     usdCheck("1").Issue(),
+		TradingFee: 500,
+	}
+	_, raw, err := Raw(vote)
+	c.Assert(err, IsNil)
+	tx, err := ReadTransaction(bytes.NewReader(raw))
+	c.Assert(err, IsNil)
+	c.Check(tx.(*AMMVote).Asset2, Equals, vote.Asset2)
+	c.Check(tx.(*AMMVote).Asset.IsNative(), Equals, true)
+}
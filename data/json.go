@@ -10,6 +10,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/rubblelabs/ripple/crypto"
 )
 
 type ledgerJSON Ledger
@@ -115,6 +117,27 @@ func (txm *TransactionWithMetaData) UnmarshalJSON(b []byte) error {
 	return json.Unmarshal(b, extract)
 }
 
+// rippled reports a delivered_amount of "unavailable" for transactions
+// in ledgers before it was recorded, leave DeliveredAmount nil for those.
+func (m *MetaData) UnmarshalJSON(b []byte) error {
+	type metaDataJSON MetaData
+	extract := struct {
+		*metaDataJSON
+		DeliveredAmount json.RawMessage `json:"delivered_amount,omitempty"`
+	}{
+		metaDataJSON: (*metaDataJSON)(m),
+	}
+	if err := json.Unmarshal(b, &extract); err != nil {
+		return err
+	}
+	m.DeliveredAmount = nil
+	if len(extract.DeliveredAmount) == 0 || string(extract.DeliveredAmount) == `"unavailable"` {
+		return nil
+	}
+	m.DeliveredAmount = new(Amount)
+	return json.Unmarshal(extract.DeliveredAmount, m.DeliveredAmount)
+}
+
 func (txm TransactionWithMetaData) marshalJSON() ([]byte, []byte, error) {
 	tx, err := json.Marshal(txm.Transaction)
 	if err != nil {
@@ -387,6 +410,11 @@ func (a *Amount) UnmarshalJSON(b []byte) (err error) {
 	if err := json.Unmarshal(b, &dummy); err != nil {
 		return err
 	}
+	// rippled has never accepted an issued amount of XRP, so no ledger
+	// holds one
+	if err := dummy.Currency.CheckIssued(); err != nil {
+		return err
+	}
 	a.Value, a.Currency, a.Issuer = &dummy.Value.Value, dummy.Currency, dummy.Issuer
 	return nil
 }
@@ -429,26 +457,97 @@ func (h *Hash256) UnmarshalText(b []byte) error {
 }
 
 func (a Account) MarshalText() ([]byte, error) {
-	address, err := a.Hash()
-	if err != nil {
-		return nil, err
-	}
-	return address.MarshalText()
+	return crypto.AppendRippleHash(make([]byte, 0, 35), crypto.RIPPLE_ACCOUNT_ID, a[:])
 }
 
-// Expects base58-encoded account id
+// Expects base58-encoded account id or a mainnet X-address without a tag
 func (a *Account) UnmarshalText(b []byte) error {
 	if len(b) == 0 {
 		return nil
 	}
-	account, err := NewAccountFromAddress(string(b))
+	account, tag, err := newAccountWithTag(string(b))
 	if err != nil {
 		return err
 	}
+	if tag != nil {
+		return fmt.Errorf("X-address with tag not allowed here: %s", b)
+	}
 	copy(a[:], account[:])
 	return nil
 }
 
+// newAccountWithTag rejects testnet X-addresses as JSON does not say which
+// network it is for. Use NewAccountFromXAddress to accept them.
+func newAccountWithTag(s string) (*Account, *uint32, error) {
+	if !crypto.IsXAddress(s) {
+		account, err := NewAccountFromAddress(s)
+		return account, nil, err
+	}
+	account, tag, testnet, err := NewAccountFromXAddress(s)
+	switch {
+	case err != nil:
+		return nil, nil, err
+	case testnet:
+		return nil, nil, fmt.Errorf("Testnet X-address not allowed here: %s", s)
+	}
+	return account, tag, nil
+}
+
+type paymentJSON Payment
+
+// Destination may be an X-address, its tag becomes the DestinationTag
+func (p *Payment) UnmarshalJSON(b []byte) error {
+	extract := &struct {
+		*paymentJSON
+		Destination string
+	}{
+		paymentJSON: (*paymentJSON)(p),
+	}
+	if err := json.Unmarshal(b, extract); err != nil {
+		return err
+	}
+	if len(extract.Destination) == 0 {
+		return nil
+	}
+	account, tag, err := newAccountWithTag(extract.Destination)
+	if err != nil {
+		return err
+	}
+	if tag != nil && p.DestinationTag != nil && *tag != *p.DestinationTag {
+		return fmt.Errorf("Conflicting destination tags: %d and %d", *tag, *p.DestinationTag)
+	}
+	if tag != nil {
+		p.DestinationTag = tag
+	}
+	p.Destination = *account
+	return nil
+}
+
+type orderBookOfferJSON OrderBookOffer
+
+// OwnerFunds is in drops when the offer's TakerGets is XRP and a decimal
+// otherwise
+func (o *OrderBookOffer) UnmarshalJSON(b []byte) error {
+	extract := &struct {
+		*orderBookOfferJSON
+		OwnerFunds *string `json:"owner_funds"`
+	}{
+		orderBookOfferJSON: (*orderBookOfferJSON)(o),
+	}
+	if err := json.Unmarshal(b, extract); err != nil {
+		return err
+	}
+	if extract.OwnerFunds == nil {
+		return nil
+	}
+	funds, err := NewValue(*extract.OwnerFunds, o.TakerGets != nil && o.TakerGets.IsNative())
+	if err != nil {
+		return err
+	}
+	o.OwnerFunds = *funds
+	return nil
+}
+
 func (r RegularKey) MarshalText() ([]byte, error) {
 	address, err := r.Hash()
 	if err != nil {
@@ -475,16 +574,38 @@ func (s Seed) MarshalText() ([]byte, error) {
 	return address.MarshalText()
 }
 
-// Expects base58-encoded account id
+// Expects base58-encoded seed or secret numbers for an ECDSA key. Ed25519
+// seeds are rejected as their key type would be lost, use TypedSeed.
 func (s *Seed) UnmarshalText(b []byte) error {
-	account, err := NewSeedFromAddress(string(b))
+	account, keyType, err := NewSeedFromAddress(string(b))
 	if err != nil {
 		return err
 	}
+	if keyType != ECDSA {
+		return fmt.Errorf("%s seed not allowed here, use TypedSeed", keyType)
+	}
 	copy(s[:], account[:])
 	return nil
 }
 
+func (t TypedSeed) MarshalText() ([]byte, error) {
+	address, err := t.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return address.MarshalText()
+}
+
+// Expects base58-encoded seed or secret numbers
+func (t *TypedSeed) UnmarshalText(b []byte) error {
+	seed, err := NewTypedSeed(string(b))
+	if err != nil {
+		return err
+	}
+	*t = *seed
+	return nil
+}
+
 func (v VariableLength) MarshalText() ([]byte, error) {
 	return b2h(v), nil
 }
@@ -503,8 +624,11 @@ func (p PublicKey) MarshalText() ([]byte, error) {
 	return b2h(p[:]), nil
 }
 
-// Expects public key hex
+// Expects public key hex, or nothing for a zero key
 func (p *PublicKey) UnmarshalText(b []byte) error {
+	if len(b) != 0 && len(b) != len(p)*2 {
+		return fmt.Errorf("Bad public key length: %d", len(b)/2)
+	}
 	_, err := hex.Decode(p[:], b)
 	return err
 }
@@ -524,3 +648,15 @@ func (h *Uint64Hex) UnmarshalText(b []byte) error {
 func (keyType KeyType) MarshalText() ([]byte, error) {
 	return []byte(keyType.String()), nil
 }
+
+func (keyType *KeyType) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case ECDSA.String():
+		*keyType = ECDSA
+	case Ed25519.String():
+		*keyType = Ed25519
+	default:
+		return fmt.Errorf("Unknown key type: %s", b)
+	}
+	return nil
+}
@@ -51,3 +51,63 @@ func (s *JSONSuite) TestLedgersJSON(c *C) {
 		compare(c, f, b, out)
 	}
 }
+
+func (s *JSONSuite) TestXAddress(c *C) {
+	const classic = "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf"
+	var p Payment
+	c.Assert(json.Unmarshal([]byte(`{"TransactionType":"Payment","Destination":"XVLhHMPHU98es4dbozjVtdWzVrDjtV8xvjGQTYPiAx6gwDC"}`), &p), IsNil)
+	c.Check(p.Destination.String(), Equals, classic)
+	c.Assert(p.DestinationTag, NotNil)
+	c.Check(*p.DestinationTag, Equals, uint32(1))
+
+	c.Check(json.Unmarshal([]byte(`{"Destination":"XVLhHMPHU98es4dbozjVtdWzVrDjtV8xvjGQTYPiAx6gwDC","DestinationTag":1}`), &p), IsNil)
+	c.Check(json.Unmarshal([]byte(`{"Destination":"XVLhHMPHU98es4dbozjVtdWzVrDjtV8xvjGQTYPiAx6gwDC","DestinationTag":2}`), &p), ErrorMatches, "Conflicting destination tags: 1 and 2")
+
+	var account Account
+	c.Assert(account.UnmarshalText([]byte("XVLhHMPHU98es4dbozjVtdWzVrDjtV5fdx1mHp98tDMoQXb")), IsNil)
+	c.Check(account.String(), Equals, classic)
+	c.Check(account.UnmarshalText([]byte("XVLhHMPHU98es4dbozjVtdWzVrDjtV8xvjGQTYPiAx6gwDC")), ErrorMatches, "X-address with tag not allowed here:.*")
+	address, err := account.XAddress(nil, true)
+	c.Assert(err, IsNil)
+	c.Check(address, Equals, "TVE26TYGhfLC7tQDno7G8dGtxSkYQn49b3qD26PK7FcGSKE")
+
+	c.Check(account.UnmarshalText([]byte(address)), ErrorMatches, "Testnet X-address not allowed here:.*")
+	one := uint32(1)
+	tagged, err := account.XAddress(&one, true)
+	c.Assert(err, IsNil)
+	c.Check(json.Unmarshal([]byte(`{"Destination":"`+tagged+`"}`), &p), ErrorMatches, "Testnet X-address not allowed here:.*")
+	testnet, tag, isTestnet, err := NewAccountFromXAddress(tagged)
+	c.Assert(err, IsNil)
+	c.Check(testnet.String(), Equals, classic)
+	c.Check(*tag, Equals, uint32(1))
+	c.Check(isTestnet, Equals, true)
+}
+
+func (s *JSONSuite) TestSecretNumbers(c *C) {
+	var action struct{ Seed Seed }
+	c.Assert(json.Unmarshal([]byte(`{"Seed":"554872 394230 209376 323698 140250 387423 652803 258676"}`), &action), IsNil)
+	c.Check(action.Seed.String(), Equals, "sn5ScMr4n1Kqc9DUJqVsGcSv7yb2U")
+	c.Check(action.Seed.SecretNumbers(), Equals, "554872 394230 209376 323698 140250 387423 652803 258676")
+	err := json.Unmarshal([]byte(`{"Seed":"554872 394230 209376 323698 140250 387423 652803 258677"}`), &action)
+	c.Check(err, ErrorMatches, `Bad secret number 8 \(258677\): bad checksum`)
+}
+
+func (s *JSONSuite) TestTypedSeed(c *C) {
+	var keys struct {
+		Seed  TypedSeed
+		Other TypedSeed
+	}
+	c.Assert(json.Unmarshal([]byte(`{"Seed":"sEdVQ4wvD1AaTG6JA54qt38TengAuiz","Other":"snoPBrXtMeMyMHUVTgbuqAfg1SUTb"}`), &keys), IsNil)
+	c.Check(keys.Seed.KeyType, Equals, Ed25519)
+	c.Check(keys.Other.KeyType, Equals, ECDSA)
+	c.Check(keys.Seed.Seed, Equals, keys.Other.Seed)
+	c.Check(keys.Seed.String(), Equals, "sEdVQ4wvD1AaTG6JA54qt38TengAuiz")
+	c.Check(keys.Seed.Seed.AccountId(keys.Seed.KeyType, nil).String(), Equals, "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf")
+	b, err := json.Marshal(keys)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `{"Seed":"sEdVQ4wvD1AaTG6JA54qt38TengAuiz","Other":"snoPBrXtMeMyMHUVTgbuqAfg1SUTb"}`)
+
+	var seed Seed
+	c.Check(seed.UnmarshalText([]byte("sEdVQ4wvD1AaTG6JA54qt38TengAuiz")), ErrorMatches, "Ed25519 seed not allowed here, use TypedSeed")
+	c.Check(seed.UnmarshalText([]byte("snoPBrXtMeMyMHUVTgbuqAfg1SUTb")), IsNil)
+}
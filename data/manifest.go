@@ -0,0 +1,211 @@
+package data
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+
+	"github.com/rubblelabs/ripple/crypto"
+)
+
+// A Manifest binds the master key of a validator to the ephemeral key with
+// which it signs validations. Both keys sign the manifest. A manifest with
+// the highest sequence revokes the master key and has no ephemeral key.
+type Manifest struct {
+	Hash            Hash256
+	PublicKey       PublicKey
+	SigningPubKey   *PublicKey
+	Sequence        uint32
+	Domain          *VariableLength
+	Signature       *VariableLength
+	MasterSignature VariableLength
+}
+
+const ManifestRevoked uint32 = 0xFFFFFFFF
+
+// Domains as accepted by rippled
+const (
+	minManifestDomain = 4
+	maxManifestDomain = 128
+)
+
+// NewManifest returns an unsigned manifest binding the master public key to
+// the ephemeral signing key. Sign it with Sign.
+func NewManifest(sequence uint32, master, signing []byte, domain string) (*Manifest, error) {
+	if sequence == ManifestRevoked {
+		return nil, fmt.Errorf("Use NewRevocation to revoke a master key")
+	}
+	m := &Manifest{
+		Sequence:      sequence,
+		SigningPubKey: new(PublicKey),
+	}
+	if err := copyPublicKey(&m.PublicKey, master); err != nil {
+		return nil, err
+	}
+	if err := copyPublicKey(m.SigningPubKey, signing); err != nil {
+		return nil, err
+	}
+	if len(domain) > 0 {
+		d := VariableLength(domain)
+		m.Domain = &d
+	}
+	return m, m.check()
+}
+
+// NewRevocation returns an unsigned manifest revoking the master public key
+func NewRevocation(master []byte) (*Manifest, error) {
+	m := &Manifest{Sequence: ManifestRevoked}
+	return m, copyPublicKey(&m.PublicKey, master)
+}
+
+func copyPublicKey(k *PublicKey, b []byte) error {
+	if len(b) != len(k) {
+		return fmt.Errorf("Bad public key length: %d", len(b))
+	}
+	copy(k[:], b)
+	return nil
+}
+
+// ReadManifest parses the binary form of a manifest
+func ReadManifest(r Reader) (*Manifest, error) {
+	m := new(Manifest)
+	v := reflect.ValueOf(m)
+	if err := readObject(r, &v); err != nil {
+		return nil, err
+	}
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+	hash, _, err := Raw(m)
+	if err != nil {
+		return nil, err
+	}
+	m.Hash = hash
+	return m, nil
+}
+
+// ParseManifest parses the base64 form of a manifest used in rippled's
+// configuration and validator lists
+func ParseManifest(s string) (*Manifest, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return ReadManifest(bytes.NewReader(b))
+}
+
+func (m *Manifest) GetType() string               { return "Manifest" }
+func (m *Manifest) Prefix() HashPrefix            { return HP_MANIFEST }
+func (m *Manifest) SigningPrefix() HashPrefix     { return HP_MANIFEST }
+func (m *Manifest) GetHash() *Hash256             { return &m.Hash }
+func (m *Manifest) GetPublicKey() *PublicKey      { return m.SigningPubKey }
+func (m *Manifest) GetSignature() *VariableLength { return m.Signature }
+func (m *Manifest) InitialiseForSigning()         {}
+func (m *Manifest) Revoked() bool                 { return m.Sequence == ManifestRevoked }
+func (m *Manifest) MasterKey() string             { return m.PublicKey.NodePublicKey() }
+
+// Bytes returns the binary form of the manifest
+func (m *Manifest) Bytes() ([]byte, error) {
+	_, b, err := Raw(m)
+	return b, err
+}
+
+// Base64 returns the form of the manifest used in rippled's configuration
+// and validator lists
+func (m *Manifest) Base64() (string, error) {
+	b, err := m.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// check returns an error if rippled would not accept the fields of the
+// manifest, leaving aside the signatures
+func (m *Manifest) check() error {
+	switch {
+	case m.Revoked() && (m.SigningPubKey != nil || m.Signature != nil):
+		return fmt.Errorf("Revocation manifest with signing key")
+	case !m.Revoked() && m.SigningPubKey == nil:
+		return fmt.Errorf("Manifest without signing key")
+	case !m.Revoked() && *m.SigningPubKey == m.PublicKey:
+		return fmt.Errorf("Manifest signing key is the master key")
+	case m.Domain != nil && (len(*m.Domain) < minManifestDomain || len(*m.Domain) > maxManifestDomain):
+		return fmt.Errorf("Bad manifest domain: %s", string(*m.Domain))
+	}
+	return nil
+}
+
+// Sign signs the manifest with the master key and, unless it is a
+// revocation, with the ephemeral signing key
+func (m *Manifest) Sign(master, signing crypto.Signer) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	m.Signature = nil
+	m.MasterSignature = nil
+	hash, msg, err := SigningHash(m)
+	if err != nil {
+		return err
+	}
+	msg = append(m.SigningPrefix().Bytes(), msg...)
+	if !m.Revoked() {
+		sig, err := sign(signing, m.SigningPubKey.Bytes(), hash.Bytes(), msg)
+		if err != nil {
+			return err
+		}
+		m.Signature = &sig
+	}
+	if m.MasterSignature, err = sign(master, m.PublicKey.Bytes(), hash.Bytes(), msg); err != nil {
+		return err
+	}
+	m.Hash, _, err = Raw(m)
+	return err
+}
+
+// Verify returns an error unless the manifest is well formed and signed by
+// both keys, or by the master key for a revocation
+func (m *Manifest) Verify() error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	hash, msg, err := SigningHash(m)
+	if err != nil {
+		return err
+	}
+	msg = append(m.SigningPrefix().Bytes(), msg...)
+	if err := verifyManifest(m.PublicKey, hash, msg, m.MasterSignature, "master signature"); err != nil {
+		return err
+	}
+	if m.Revoked() {
+		return nil
+	}
+	if m.Signature == nil {
+		return fmt.Errorf("Manifest without signature")
+	}
+	return verifyManifest(*m.SigningPubKey, hash, msg, *m.Signature, "signature")
+}
+
+func verifyManifest(key PublicKey, hash Hash256, msg []byte, sig VariableLength, name string) error {
+	ok, err := crypto.Verify(key.Bytes(), hash.Bytes(), msg, sig)
+	switch {
+	case err != nil:
+		return fmt.Errorf("Bad manifest %s: %s", name, err)
+	case !ok:
+		return fmt.Errorf("Bad manifest %s", name)
+	default:
+		return nil
+	}
+}
+
+func (m *Manifest) String() string {
+	if m.Revoked() {
+		return fmt.Sprintf("%s revoked", m.MasterKey())
+	}
+	s := fmt.Sprintf("%s %d %s", m.MasterKey(), m.Sequence, m.SigningPubKey.NodePublicKey())
+	if m.Domain != nil {
+		s += " " + string(*m.Domain)
+	}
+	return s
+}
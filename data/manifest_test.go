@@ -0,0 +1,104 @@
+package data
+
+import (
+	"bytes"
+
+	"github.com/rubblelabs/ripple/crypto"
+	. "gopkg.in/check.v1"
+)
+
+type ManifestSuite struct{}
+
+var _ = Suite(&ManifestSuite{})
+
+func manifestKeys(c *C) (master, signing *crypto.KeySigner) {
+	masterKey, err := crypto.NewEd25519Key([]byte("master"))
+	c.Assert(err, IsNil)
+	signingKey, err := crypto.NewECDSAKey([]byte("signing"))
+	c.Assert(err, IsNil)
+	return crypto.NewKeySigner(masterKey, nil), crypto.NewKeySigner(signingKey, nil)
+}
+
+func (s *ManifestSuite) TestSignAndParse(c *C) {
+	master, signing := manifestKeys(c)
+	m, err := NewManifest(1, master.Public(), signing.Public(), "example.com")
+	c.Assert(err, IsNil)
+	c.Check(m.Verify(), ErrorMatches, "Bad manifest master signature.*")
+	c.Assert(m.Sign(master, signing), IsNil)
+	c.Check(m.Verify(), IsNil)
+
+	b, err := m.Bytes()
+	c.Assert(err, IsNil)
+	// sfSequence, then sfPublicKey
+	c.Check(string(b2h(b[:7])), Equals, "24000000017121")
+	c.Check(bytes.HasSuffix(b, m.MasterSignature), Equals, true)
+
+	encoded, err := m.Base64()
+	c.Assert(err, IsNil)
+	parsed, err := ParseManifest(encoded)
+	c.Assert(err, IsNil)
+	c.Check(parsed, DeepEquals, m)
+	c.Check(parsed.Verify(), IsNil)
+	c.Check(parsed.String(), Equals, m.MasterKey()+" 1 "+m.SigningPubKey.NodePublicKey()+" example.com")
+
+	parsed.Sequence = 2
+	c.Check(parsed.Verify(), ErrorMatches, "Bad manifest master signature")
+	parsed.Sequence = 1
+	(*parsed.Signature)[10]++
+	c.Check(parsed.Verify(), ErrorMatches, "Bad manifest signature.*")
+}
+
+func (s *ManifestSuite) TestRevocation(c *C) {
+	master, signing := manifestKeys(c)
+	m, err := NewRevocation(master.Public())
+	c.Assert(err, IsNil)
+	c.Assert(m.Sign(master, nil), IsNil)
+	c.Check(m.Revoked(), Equals, true)
+	c.Check(m.Signature, IsNil)
+	c.Check(m.Verify(), IsNil)
+	encoded, err := m.Base64()
+	c.Assert(err, IsNil)
+	parsed, err := ParseManifest(encoded)
+	c.Assert(err, IsNil)
+	c.Check(parsed.Verify(), IsNil)
+	c.Check(parsed.String(), Equals, m.MasterKey()+" revoked")
+
+	m.SigningPubKey = new(PublicKey)
+	copy(m.SigningPubKey[:], signing.Public())
+	c.Check(m.Verify(), ErrorMatches, "Revocation manifest with signing key")
+	_, err = NewManifest(ManifestRevoked, master.Public(), signing.Public(), "")
+	c.Check(err, ErrorMatches, "Use NewRevocation to revoke a master key")
+}
+
+func (s *ManifestSuite) TestBadManifest(c *C) {
+	master, signing := manifestKeys(c)
+	_, err := NewManifest(1, master.Public(), master.Public(), "")
+	c.Check(err, ErrorMatches, "Manifest signing key is the master key")
+	_, err = NewManifest(1, master.Public(), signing.Public(), "a.b")
+	c.Check(err, ErrorMatches, "Bad manifest domain: a.b")
+	_, err = NewManifest(1, master.Public()[1:], signing.Public(), "")
+	c.Check(err, ErrorMatches, "Bad public key length: 32")
+}
+
+// The example validator token manifest from the documentation of rippled's
+// validator-keys tool. Both signatures verify so it is as generated by the
+// tool. The hash was checked independently as the SHA-512Half of "MAN\0"
+// and the decoded bytes.
+const publishedManifest = "JAAAAAFxIe1FtwmimvGtH2iCcMJqC9gVFKilGfw1/vCxHXXLplc2GnMhAkE1agqXxBwDwDbID6OMSYuM0FDAlpAgNk8SKFn7MO2fdkcwRQIhAOngu9sAKqXYouJ+l2V0W+sAOkVB+ZRS6PShlJAfUsXfAiBsVJGesaadOJc/aAZokS1vymGmVrlHPKWX3Yywu6in8HASQKPugBD67kMaRFGvmpATHlGKJdvDFlWPYy5AqDedFv5TJa2w0i21eq3MYywLVJZnFOr7C0kw2AiTzSCjIzditQ8="
+
+func (s *ManifestSuite) TestPublished(c *C) {
+	m, err := ParseManifest(publishedManifest)
+	c.Assert(err, IsNil)
+	c.Check(m.Sequence, Equals, uint32(1))
+	c.Check(m.MasterKey(), Equals, "nHBt9fsb4849WmZiCds4r5TXyBeQjqnH5kzPtqgMAQMgi39YZRPa")
+	c.Check(m.PublicKey.String(), Equals, "ED45B709A29AF1AD1F688270C26A0BD81514A8A519FC35FEF0B11D75CBA657361A")
+	c.Assert(m.SigningPubKey, NotNil)
+	c.Check(m.SigningPubKey.NodePublicKey(), Equals, "n9KsDYGKhABVc4wK5u3MnVhgPinyJimyKGpr9VJYuBaY8EnJXR2x")
+	c.Check(m.Domain, IsNil)
+	c.Check(m.Hash.String(), Equals, "EA2F2BE37F4B80F7AAF0FD1261C86590B17FE5F1CC0B468018014699C141D304")
+	c.Check(m.Verify(), IsNil)
+
+	encoded, err := m.Base64()
+	c.Assert(err, IsNil)
+	c.Check(encoded, Equals, publishedManifest)
+}
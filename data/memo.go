@@ -1,5 +1,27 @@
 package data
 
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Common values for MemoFormat
+const (
+	MemoFormatText = "text/plain"
+	MemoFormatJSON = "application/json"
+	MemoFormatHex  = "hex"
+)
+
+// rippled rejects transactions with larger serialized memos
+const maxMemoSize = 1024
+
+// Characters allowed in MemoType and MemoFormat, as for URLs
+const memoURLCharacters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~:/?#[]@!$&'()*+,;=%"
+
 type MemoItem struct {
 	MemoType   VariableLength
 	MemoData   VariableLength
@@ -11,3 +33,239 @@ type Memo struct {
 }
 
 type Memos []Memo
+
+func newMemo(memoType, format string, data []byte) (*Memo, error) {
+	memo := &Memo{
+		Memo: MemoItem{
+			MemoType:   VariableLength(memoType),
+			MemoData:   VariableLength(data),
+			MemoFormat: VariableLength(format),
+		},
+	}
+	if err := (Memos{*memo}).Check(); err != nil {
+		return nil, err
+	}
+	return memo, nil
+}
+
+// NewTextMemo returns a text/plain memo. text must be valid UTF-8.
+func NewTextMemo(memoType, text string) (*Memo, error) {
+	if !utf8.ValidString(text) {
+		return nil, fmt.Errorf("Memo text is not valid UTF-8")
+	}
+	return newMemo(memoType, MemoFormatText, []byte(text))
+}
+
+// NewJSONMemo returns an application/json memo holding v
+func NewJSONMemo(memoType string, v interface{}) (*Memo, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return newMemo(memoType, MemoFormatJSON, b)
+}
+
+// NewHexMemo returns a memo holding the binary data encoded in s
+func NewHexMemo(memoType, s string) (*Memo, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return newMemo(memoType, MemoFormatHex, b)
+}
+
+// Type returns the MemoType as text
+func (m *MemoItem) Type() string {
+	return string(m.MemoType)
+}
+
+// Format returns the MemoFormat as text
+func (m *MemoItem) Format() string {
+	return string(m.MemoFormat)
+}
+
+// Text returns the MemoData when it is valid UTF-8
+func (m *MemoItem) Text() (string, error) {
+	if !utf8.Valid(m.MemoData) {
+		return "", fmt.Errorf("Memo data is not valid UTF-8")
+	}
+	return string(m.MemoData), nil
+}
+
+// JSON unmarshals the MemoData into v
+func (m *MemoItem) JSON(v interface{}) error {
+	return json.Unmarshal(m.MemoData, v)
+}
+
+// Data returns the MemoData as text unless it is binary or marked as hex
+func (m *MemoItem) Data() (string, bool) {
+	if m.Format() == MemoFormatHex || !printable(m.MemoData) {
+		return "", false
+	}
+	return string(m.MemoData), true
+}
+
+func printable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Freeform returns the MemoData as a JSON document when it is marked as
+// one and otherwise as text or hex
+func (m *MemoItem) Freeform() Freeform {
+	text, ok := m.Data()
+	switch {
+	case len(m.MemoData) == 0:
+		return nil
+	case ok && m.Format() == MemoFormatJSON && json.Valid(m.MemoData):
+		return Freeform(m.MemoData)
+	case ok:
+		b, _ := json.Marshal(text)
+		return Freeform(b)
+	default:
+		return Freeform(`"` + string(b2h(m.MemoData)) + `"`)
+	}
+}
+
+func (m *MemoItem) label() []string {
+	var parts []string
+	if len(m.MemoType) > 0 {
+		parts = append(parts, m.Type())
+	}
+	if len(m.MemoFormat) > 0 {
+		parts = append(parts, "("+m.Format()+")")
+	}
+	return parts
+}
+
+// String shows the memo as text where possible and as hex otherwise
+func (m MemoItem) String() string {
+	parts := m.label()
+	if text, ok := m.Data(); ok {
+		parts = append(parts, fmt.Sprintf("%q", text))
+	} else if len(m.MemoData) > 0 {
+		parts = append(parts, string(b2h(m.MemoData)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (m Memos) String() string {
+	var s []string
+	for _, memo := range m {
+		s = append(s, memo.Memo.String())
+	}
+	return fmt.Sprintf("[%s]", strings.Join(s, ", "))
+}
+
+// Readable returns m for display
+func (m Memos) Readable() ReadableMemos {
+	return ReadableMemos(m)
+}
+
+// ReadableMemos are memos for display. The JSON adds the readable forms of
+// each field next to the protocol fields, which are left intact. The extra
+// fields are not part of the protocol, so Memos stays the default.
+type ReadableMemos Memos
+
+type memoItemJSON MemoItem
+
+type readableMemoItem struct {
+	memoItemJSON
+	ParsedMemoType   string   `json:"parsed_memo_type,omitempty"`
+	ParsedMemoFormat string   `json:"parsed_memo_format,omitempty"`
+	ParsedMemoData   Freeform `json:"parsed_memo_data,omitempty"`
+}
+
+func (m ReadableMemos) MarshalJSON() ([]byte, error) {
+	memos := make([]struct{ Memo readableMemoItem }, len(m))
+	for i := range m {
+		item := &m[i].Memo
+		memos[i].Memo = readableMemoItem{
+			memoItemJSON:   memoItemJSON(*item),
+			ParsedMemoData: item.Freeform(),
+		}
+		if printable(item.MemoType) {
+			memos[i].Memo.ParsedMemoType = item.Type()
+		}
+		if printable(item.MemoFormat) {
+			memos[i].Memo.ParsedMemoFormat = item.Format()
+		}
+	}
+	return json.Marshal(memos)
+}
+
+// String shows JSON memos as their documents and the others as MemoItem does
+func (m ReadableMemos) String() string {
+	var s []string
+	for i := range m {
+		item := &m[i].Memo
+		if item.Format() != MemoFormatJSON {
+			s = append(s, item.String())
+			continue
+		}
+		s = append(s, strings.Join(append(item.label(), item.Freeform().String()), " "))
+	}
+	return fmt.Sprintf("[%s]", strings.Join(s, ", "))
+}
+
+// Freeform is a JSON value of no fixed type
+type Freeform json.RawMessage
+
+func (f Freeform) MarshalJSON() ([]byte, error) {
+	if len(f) == 0 {
+		return []byte("null"), nil
+	}
+	return f, nil
+}
+
+func (f Freeform) String() string {
+	return string(f)
+}
+
+// Check returns an error if rippled would reject the memos
+func (m Memos) Check() error {
+	var size int
+	for _, memo := range m {
+		for _, field := range []struct {
+			name  string
+			value VariableLength
+		}{
+			{"MemoType", memo.Memo.MemoType},
+			{"MemoFormat", memo.Memo.MemoFormat},
+			{"MemoData", memo.Memo.MemoData},
+		} {
+			if len(field.value) == 0 {
+				continue
+			}
+			if field.name != "MemoData" && strings.Trim(string(field.value), memoURLCharacters) != "" {
+				return fmt.Errorf("Bad character in %s: %q", field.name, field.value)
+			}
+			size += 1 + variableLengthSize(len(field.value)) + len(field.value)
+		}
+		// Object header and end marker
+		size += 2
+	}
+	if size > maxMemoSize {
+		return fmt.Errorf("Memos too large: %d bytes", size)
+	}
+	return nil
+}
+
+// variableLengthSize returns the size of the length prefix for n bytes
+func variableLengthSize(n int) int {
+	switch {
+	case n <= 192:
+		return 1
+	case n <= 12480:
+		return 2
+	default:
+		return 3
+	}
+}
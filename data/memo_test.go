@@ -0,0 +1,83 @@
+package data
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type MemoSuite struct{}
+
+var _ = Suite(&MemoSuite{})
+
+func (s *MemoSuite) TestText(c *C) {
+	memo, err := NewTextMemo("invoice", "Thanks for the coffee ☕")
+	c.Assert(err, IsNil)
+	text, err := memo.Memo.Text()
+	c.Assert(err, IsNil)
+	c.Check(text, Equals, "Thanks for the coffee ☕")
+	c.Check(memo.Memo.Format(), Equals, MemoFormatText)
+	c.Check(memo.Memo.String(), Equals, `invoice (text/plain) "Thanks for the coffee ☕"`)
+
+	_, err = NewTextMemo("invoice", "\xff")
+	c.Check(err, ErrorMatches, "Memo text is not valid UTF-8")
+	_, err = NewTextMemo("invoice", strings.Repeat("a", maxMemoSize))
+	c.Check(err, ErrorMatches, "Memos too large: .*")
+	_, err = NewTextMemo("bad type", "a")
+	c.Check(err, ErrorMatches, "Bad character in MemoType: .*")
+}
+
+func (s *MemoSuite) TestJSON(c *C) {
+	memo, err := NewJSONMemo("order", map[string]int{"id": 42})
+	c.Assert(err, IsNil)
+	var v struct{ Id int }
+	c.Assert(memo.Memo.JSON(&v), IsNil)
+	c.Check(v.Id, Equals, 42)
+	data, ok := memo.Memo.Data()
+	c.Check(data, Equals, `{"id":42}`)
+	c.Check(ok, Equals, true)
+
+	b, err := json.Marshal(memo)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `{"Memo":{"MemoType":"6F72646572","MemoData":"7B226964223A34327D","MemoFormat":"6170706C69636174696F6E2F6A736F6E"}}`)
+	var decoded Memo
+	c.Assert(json.Unmarshal(b, &decoded), IsNil)
+	c.Check(decoded, DeepEquals, *memo)
+}
+
+func (s *MemoSuite) TestHex(c *C) {
+	memo, err := NewHexMemo("", "DEADBEEF")
+	c.Assert(err, IsNil)
+	c.Check(memo.Memo.String(), Equals, "(hex) DEADBEEF")
+	_, err = memo.Memo.Text()
+	c.Check(err, NotNil)
+	_, ok := memo.Memo.Data()
+	c.Check(ok, Equals, false)
+	c.Check(Memos{*memo, *memo}.String(), Equals, "[(hex) DEADBEEF, (hex) DEADBEEF]")
+	_, err = NewHexMemo("", "XYZ")
+	c.Check(err, NotNil)
+}
+
+func (s *MemoSuite) TestReadable(c *C) {
+	order, err := NewJSONMemo("order", map[string]int{"id": 42})
+	c.Assert(err, IsNil)
+	text, err := NewTextMemo("invoice", "Thanks")
+	c.Assert(err, IsNil)
+	binary, err := NewHexMemo("", "DEADBEEF")
+	c.Assert(err, IsNil)
+	memos := Memos{*order, *text, *binary}
+
+	b, err := json.Marshal(memos.Readable())
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `[`+
+		`{"Memo":{"MemoType":"6F72646572","MemoData":"7B226964223A34327D","MemoFormat":"6170706C69636174696F6E2F6A736F6E","parsed_memo_type":"order","parsed_memo_format":"application/json","parsed_memo_data":{"id":42}}},`+
+		`{"Memo":{"MemoType":"696E766F696365","MemoData":"5468616E6B73","MemoFormat":"746578742F706C61696E","parsed_memo_type":"invoice","parsed_memo_format":"text/plain","parsed_memo_data":"Thanks"}},`+
+		`{"Memo":{"MemoType":"","MemoData":"DEADBEEF","MemoFormat":"686578","parsed_memo_format":"hex","parsed_memo_data":"DEADBEEF"}}]`)
+	c.Check(memos.Readable().String(), Equals, `[order (application/json) {"id":42}, invoice (text/plain) "Thanks", (hex) DEADBEEF]`)
+
+	// The protocol fields are unmarshalled and the readable ones ignored
+	var decoded Memos
+	c.Assert(json.Unmarshal(b, &decoded), IsNil)
+	c.Check(decoded, DeepEquals, memos)
+}
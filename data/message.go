@@ -0,0 +1,85 @@
+package data
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/rubblelabs/ripple/crypto"
+)
+
+// A SignedMessage proves control of an account by signing a message, such
+// as a challenge from a third party. The message is framed with a prefix
+// which no ledger object uses and a domain, so that the signature can
+// neither be replayed as a transaction nor for another purpose.
+type SignedMessage struct {
+	Message   VariableLength
+	Domain    string `json:",omitempty"`
+	PublicKey PublicKey
+	Account   Account
+	Signature VariableLength
+}
+
+func messageSigningData(message []byte, domain string) []byte {
+	b := make([]byte, 0, 8+len(domain)+len(message))
+	b = append(b, HP_MESSAGE.Bytes()...)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(domain)))
+	b = append(b, domain...)
+	return append(b, message...)
+}
+
+// SignMessage signs message for domain, which may be empty, with the key
+// publicKey held by signer
+func SignMessage(message []byte, domain string, signer crypto.Signer, publicKey []byte) (*SignedMessage, error) {
+	m := &SignedMessage{
+		Message: message,
+		Domain:  domain,
+	}
+	if err := copyPublicKey(&m.PublicKey, publicKey); err != nil {
+		return nil, err
+	}
+	msg := messageSigningData(message, domain)
+	sig, err := sign(signer, publicKey, crypto.Sha512Half(msg), msg)
+	if err != nil {
+		return nil, err
+	}
+	m.Signature = sig
+	m.Account = m.PublicKey.Account()
+	return m, nil
+}
+
+// SignMessage signs message for domain with the key of the account of
+// keyType derived from the seed
+func (s *Seed) SignMessage(keyType KeyType, message []byte, domain string) (*SignedMessage, error) {
+	var sequence *uint32
+	// Ed25519 keys have no account families
+	if keyType == ECDSA {
+		sequence = new(uint32)
+	}
+	signer := crypto.NewKeySigner(s.Key(keyType), sequence)
+	return SignMessage(message, domain, signer, signer.Public())
+}
+
+// Verify returns an error unless the message is signed by the key of the
+// account for the domain
+func (m *SignedMessage) Verify() error {
+	return VerifyMessage(m.Account, m.Message, m.Domain, m.PublicKey, m.Signature)
+}
+
+// VerifyMessage returns an error unless signature is that of publicKey for
+// message and domain, and publicKey derives account. Accounts whose
+// regular key signed the message are not recognised.
+func VerifyMessage(account Account, message []byte, domain string, publicKey PublicKey, signature VariableLength) error {
+	if publicKey.Account() != account {
+		return fmt.Errorf("Public key %s is not for %s", publicKey, account)
+	}
+	msg := messageSigningData(message, domain)
+	ok, err := crypto.Verify(publicKey.Bytes(), crypto.Sha512Half(msg), msg, signature)
+	switch {
+	case err != nil:
+		return err
+	case !ok:
+		return fmt.Errorf("Bad message signature")
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,48 @@
+package data
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type MessageSuite struct{}
+
+var _ = Suite(&MessageSuite{})
+
+func (s *MessageSuite) TestSigningData(c *C) {
+	c.Check(string(b2h(messageSigningData([]byte("hi"), "a.b"))), Equals, "4D53470000000003612E626869")
+	c.Check(string(b2h(messageSigningData([]byte("a.bhi"), ""))), Equals, "4D53470000000000612E626869")
+}
+
+func (s *MessageSuite) TestSignMessage(c *C) {
+	seed, _, err := NewSeedFromAddress("snoPBrXtMeMyMHUVTgbuqAfg1SUTb")
+	c.Assert(err, IsNil)
+	for _, test := range []struct {
+		keyType KeyType
+		account string
+	}{
+		{ECDSA, "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"},
+		{Ed25519, "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf"},
+	} {
+		m, err := seed.SignMessage(test.keyType, []byte("challenge"), "example.com")
+		c.Assert(err, IsNil)
+		c.Check(m.Account.String(), Equals, test.account)
+		c.Check(m.Verify(), IsNil)
+
+		b, err := json.Marshal(m)
+		c.Assert(err, IsNil)
+		var parsed SignedMessage
+		c.Assert(json.Unmarshal(b, &parsed), IsNil)
+		c.Check(parsed.Verify(), IsNil)
+
+		parsed.Domain = "example.org"
+		c.Check(parsed.Verify(), ErrorMatches, "Bad message signature")
+		parsed.Domain = m.Domain
+		parsed.Message = VariableLength("other")
+		c.Check(parsed.Verify(), ErrorMatches, "Bad message signature")
+		parsed.Message = m.Message
+		parsed.Account = accountCheck(testIssuer)
+		c.Check(parsed.Verify(), ErrorMatches, "Public key .* is not for .*")
+	}
+}
@@ -1,6 +1,110 @@
 package data
 
+import (
+	"encoding/binary"
+	"fmt"
+)
+
 type NFToken struct {
 	NFTokenID *Hash256        `json:",omitempty"`
 	URI       *VariableLength `json:",omitempty"`
-}
\ No newline at end of file
+}
+
+// NFTokenIDFields are the fields packed into an NFTokenID: two bytes of
+// flags, two of transfer fee, the issuer, the scrambled taxon and the
+// sequence of the token among those minted by the issuer.
+type NFTokenIDFields struct {
+	Flags       TransactionFlag
+	TransferFee uint16
+	Issuer      Account
+	Taxon       uint32
+	Sequence    uint32
+}
+
+// The taxon is scrambled with a linear congruential generator seeded by
+// the sequence so that tokens with the same taxon do not sit together in
+// NFTokenPages.
+func scrambleTaxon(taxon, sequence uint32) uint32 {
+	return taxon ^ (384160001*sequence + 2459)
+}
+
+// ParseNFTokenID returns the fields of id
+func ParseNFTokenID(id Hash256) NFTokenIDFields {
+	f := NFTokenIDFields{
+		Flags:       TransactionFlag(binary.BigEndian.Uint16(id[0:2])),
+		TransferFee: binary.BigEndian.Uint16(id[2:4]),
+		Sequence:    binary.BigEndian.Uint32(id[28:32]),
+	}
+	copy(f.Issuer[:], id[4:24])
+	f.Taxon = scrambleTaxon(binary.BigEndian.Uint32(id[24:28]), f.Sequence)
+	return f
+}
+
+// ScrambledTaxon returns the taxon as it appears in the NFTokenID
+func (f NFTokenIDFields) ScrambledTaxon() uint32 {
+	return scrambleTaxon(f.Taxon, f.Sequence)
+}
+
+// ID returns the NFTokenID holding f
+func (f NFTokenIDFields) ID() Hash256 {
+	var id Hash256
+	binary.BigEndian.PutUint16(id[0:2], uint16(f.Flags))
+	binary.BigEndian.PutUint16(id[2:4], f.TransferFee)
+	copy(id[4:24], f.Issuer[:])
+	binary.BigEndian.PutUint32(id[24:28], f.ScrambledTaxon())
+	binary.BigEndian.PutUint32(id[28:32], f.Sequence)
+	return id
+}
+
+// NewNFTokenID returns the NFTokenID which tx mints. minted is the
+// MintedNFTokens of the issuer before tx is applied, plus its
+// FirstNFTokenSequence when the issuer has one.
+func NewNFTokenID(tx *NFTokenMint, minted uint32) (*Hash256, error) {
+	if tx.NFTokenTaxon == nil {
+		return nil, fmt.Errorf("NFTokenMint has no NFTokenTaxon")
+	}
+	f := NFTokenIDFields{
+		Issuer:   tx.Account,
+		Taxon:    *tx.NFTokenTaxon,
+		Sequence: minted,
+	}
+	if tx.Flags != nil {
+		f.Flags = *tx.Flags & 0xFFFF
+	}
+	if tx.TransferFee != nil {
+		f.TransferFee = *tx.TransferFee
+	}
+	if tx.Issuer != nil {
+		f.Issuer = *tx.Issuer
+	}
+	id := f.ID()
+	return &id, nil
+}
+
+// Find returns the token with id held in the page, if any
+func (p *NFTokenPage) Find(id Hash256) *NFToken {
+	for i := range p.NFTokens {
+		if token := &p.NFTokens[i]; token.NFTokenID != nil && *token.NFTokenID == id {
+			return token
+		}
+	}
+	return nil
+}
+
+// FindNFTokenPage returns the page of owner which would hold the token with
+// id. It is the page with the lowest index no lower than that given by
+// GetNFTokenPageIndex. pages may be in any order and include pages of
+// other owners.
+func FindNFTokenPage(owner Account, id Hash256, pages []*NFTokenPage) *NFTokenPage {
+	min, max := GetNFTokenPageIndex(owner, id), GetNFTokenPageMaxIndex(owner)
+	var found *NFTokenPage
+	for _, page := range pages {
+		index := page.GetLedgerIndex()
+		switch {
+		case index == nil, index.Compare(*min) < 0, index.Compare(*max) > 0:
+		case found == nil || index.Compare(*found.GetLedgerIndex()) < 0:
+			found = page
+		}
+	}
+	return found
+}
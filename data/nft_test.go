@@ -0,0 +1,66 @@
+package data
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type NFTSuite struct{}
+
+var _ = Suite(&NFTSuite{})
+
+const nftID = "000B0539C35B55AA096BA6D87A6E6C965A6534150DC56E5E12C5D09E0000000C"
+
+func (s *NFTSuite) TestParseNFTokenID(c *C) {
+	id, err := NewHash256(nftID)
+	c.Assert(err, IsNil)
+	f := ParseNFTokenID(*id)
+	c.Check(f.Flags, Equals, TxBurnable|TxOnlyXRP|TxTransferable)
+	c.Check(f.TransferFee, Equals, uint16(1337))
+	c.Check(f.Issuer.String(), Equals, "rJoxBSzpXhPtAuqFmqxQtGKjA13jUJWthE")
+	c.Check(f.Taxon, Equals, uint32(1337))
+	c.Check(f.ScrambledTaxon(), Equals, uint32(0x12C5D09E))
+	c.Check(f.Sequence, Equals, uint32(12))
+	c.Check(f.ID(), Equals, *id)
+}
+
+func (s *NFTSuite) TestNewNFTokenID(c *C) {
+	flags := TxBurnable | TxOnlyXRP | TxTransferable | TxCanonicalSignature
+	taxon, fee := uint32(1337), uint16(1337)
+	mint := &NFTokenMint{
+		TxBase:       TxBase{TransactionType: NFTOKEN_MINT, Flags: &flags, Account: accountCheck(testAccount)},
+		NFTokenTaxon: &taxon,
+		TransferFee:  &fee,
+	}
+	issuer := accountCheck("rJoxBSzpXhPtAuqFmqxQtGKjA13jUJWthE")
+	mint.Issuer = &issuer
+	id, err := NewNFTokenID(mint, 12)
+	c.Assert(err, IsNil)
+	c.Check(id.String(), Equals, nftID)
+
+	mint.NFTokenTaxon = nil
+	_, err = NewNFTokenID(mint, 12)
+	c.Check(err, NotNil)
+}
+
+func (s *NFTSuite) TestFindNFTokenPage(c *C) {
+	id, err := NewHash256(nftID)
+	c.Assert(err, IsNil)
+	owner := accountCheck(testAccount)
+	other := accountCheck(testIssuer)
+	page := func(owner Account, low byte) *NFTokenPage {
+		var max Hash256
+		for i := range max {
+			max[i] = low
+		}
+		return &NFTokenPage{leBase: leBase{LedgerEntryType: NFTOKEN_PAGE, LedgerIndex: GetNFTokenPageIndex(owner, max)}}
+	}
+	first, last := page(owner, 0x10), page(owner, 0xFF)
+	first.NFTokens = []NFToken{{NFTokenID: id}}
+	pages := []*NFTokenPage{last, page(other, 0x20), first}
+	// The low 96 bits of the id start with 0x0D so it is on the first page
+	c.Check(FindNFTokenPage(owner, *id, pages), Equals, first)
+	c.Check(FindNFTokenPage(owner, *id, pages[:2]), Equals, last)
+	c.Check(FindNFTokenPage(owner, *id, pages[1:2]), IsNil)
+	c.Check(first.Find(*id), NotNil)
+	c.Check(last.Find(*id), IsNil)
+}
@@ -0,0 +1,368 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// RoundingMode selects how a Number is rounded when a result has more
+// significant digits than fit in its mantissa, as rippled's
+// Number::rounding_mode.
+type RoundingMode uint8
+
+const (
+	RoundToNearest   RoundingMode = iota // Ties go to the even mantissa
+	RoundTowardsZero                     // Truncate
+	RoundDownward                        // Towards negative infinity
+	RoundUpward                          // Towards positive infinity
+)
+
+const (
+	numberMinMantissa uint64 = 1000000000000000
+	numberMaxMantissa uint64 = 9999999999999999
+	numberMinExponent int    = -32768
+	numberMaxExponent int    = 32768
+)
+
+// Number is the decimal floating point type which rippled uses for IOU
+// arithmetic once the fixUniversalNumber amendment is enabled. The mantissa
+// of a non-zero Number is in the range [1e15,1e16). Unlike Value, every
+// operation rounds according to a RoundingMode.
+type Number struct {
+	mantissa int64
+	exponent int
+}
+
+var zeroNumber = Number{exponent: math.MinInt32}
+
+// guard holds the decimal digits shifted off the end of a mantissa, one
+// digit per nibble, to decide how to round.
+type guard struct {
+	digits uint64
+	xbit   bool // A non-zero digit has been shifted off the end of digits
+	sbit   bool // The number being rounded is negative
+}
+
+func (g *guard) push(d uint64) {
+	g.xbit = g.xbit || g.digits&0xF != 0
+	g.digits >>= 4
+	g.digits |= (d & 0xF) << 60
+}
+
+func (g *guard) pop() uint64 {
+	d := g.digits >> 60
+	g.digits <<= 4
+	return d
+}
+
+// round returns 1 to round the magnitude up, -1 to leave it and 0 for a tie
+func (g *guard) round(mode RoundingMode) int {
+	inexact := g.digits > 0 || g.xbit
+	switch mode {
+	case RoundTowardsZero:
+		return -1
+	case RoundDownward:
+		if g.sbit && inexact {
+			return 1
+		}
+		return -1
+	case RoundUpward:
+		if !g.sbit && inexact {
+			return 1
+		}
+		return -1
+	}
+	switch {
+	case g.digits > 0x5000000000000000:
+		return 1
+	case g.digits < 0x5000000000000000:
+		return -1
+	case g.xbit:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// NewNumber returns mantissa*10^exponent normalised and rounded with mode
+func NewNumber(mantissa int64, exponent int, mode RoundingMode) (Number, error) {
+	if mantissa < 0 {
+		return newNumber(true, uint64(-mantissa), exponent, mode)
+	}
+	return newNumber(false, uint64(mantissa), exponent, mode)
+}
+
+func newNumber(negative bool, m uint64, e int, mode RoundingMode) (Number, error) {
+	if m == 0 {
+		return zeroNumber, nil
+	}
+	for m < numberMinMantissa && e > numberMinExponent {
+		m *= 10
+		e--
+	}
+	g := guard{sbit: negative}
+	for m > numberMaxMantissa {
+		if e >= numberMaxExponent {
+			return zeroNumber, fmt.Errorf("Number overflow: %d", e)
+		}
+		g.push(m % 10)
+		m /= 10
+		e++
+	}
+	if e < numberMinExponent || m < numberMinMantissa {
+		return zeroNumber, nil
+	}
+	if r := g.round(mode); r == 1 || (r == 0 && m&1 == 1) {
+		m++
+		if m > numberMaxMantissa {
+			m /= 10
+			e++
+		}
+	}
+	if e > numberMaxExponent {
+		return zeroNumber, fmt.Errorf("Number overflow: %d", e)
+	}
+	return signedNumber(negative, m, e), nil
+}
+
+func signedNumber(negative bool, m uint64, e int) Number {
+	if negative {
+		return Number{mantissa: -int64(m), exponent: e}
+	}
+	return Number{mantissa: int64(m), exponent: e}
+}
+
+func (x Number) Mantissa() int64 { return x.mantissa }
+func (x Number) Exponent() int   { return x.exponent }
+func (x Number) IsZero() bool    { return x.mantissa == 0 }
+
+func (x Number) abs() (uint64, bool) {
+	if x.mantissa < 0 {
+		return uint64(-x.mantissa), true
+	}
+	return uint64(x.mantissa), false
+}
+
+func (x Number) Negate() Number {
+	if x.IsZero() {
+		return x
+	}
+	return Number{mantissa: -x.mantissa, exponent: x.exponent}
+}
+
+func (x Number) String() string {
+	if x.IsZero() {
+		return "0"
+	}
+	return fmt.Sprintf("%de%d", x.mantissa, x.exponent)
+}
+
+// Add returns x+y
+func (x Number) Add(y Number, mode RoundingMode) (Number, error) {
+	switch {
+	case y.IsZero():
+		return x, nil
+	case x.IsZero():
+		return y, nil
+	case x == y.Negate():
+		return zeroNumber, nil
+	}
+	xm, xn := x.abs()
+	ym, yn := y.abs()
+	xe, ye := x.exponent, y.exponent
+	var g guard
+	if xe < ye {
+		g.sbit = xn
+		for ; xe < ye; xe++ {
+			g.push(xm % 10)
+			xm /= 10
+		}
+	} else if xe > ye {
+		g.sbit = yn
+		for ; ye < xe; ye++ {
+			g.push(ym % 10)
+			ym /= 10
+		}
+	}
+	if xn == yn {
+		xm += ym
+		if xm > numberMaxMantissa {
+			g.push(xm % 10)
+			xm /= 10
+			xe++
+		}
+		if r := g.round(mode); r == 1 || (r == 0 && xm&1 == 1) {
+			xm++
+			if xm > numberMaxMantissa {
+				xm /= 10
+				xe++
+			}
+		}
+		if xe > numberMaxExponent {
+			return zeroNumber, fmt.Errorf("Number addition overflow: %d", xe)
+		}
+	} else {
+		if xm > ym {
+			xm -= ym
+		} else {
+			xm, xe, xn = ym-xm, ye, yn
+		}
+		for xm < numberMinMantissa {
+			xm = xm*10 - g.pop()
+			xe--
+		}
+		if r := g.round(mode); r == 1 || (r == 0 && xm&1 == 1) {
+			xm--
+			if xm < numberMinMantissa {
+				xm = numberMaxMantissa
+				xe--
+			}
+		}
+		if xe < numberMinExponent {
+			return zeroNumber, nil
+		}
+	}
+	return signedNumber(xn, xm, xe), nil
+}
+
+// Sub returns x-y
+func (x Number) Sub(y Number, mode RoundingMode) (Number, error) {
+	return x.Add(y.Negate(), mode)
+}
+
+// Mul returns x*y
+func (x Number) Mul(y Number, mode RoundingMode) (Number, error) {
+	switch {
+	case x.IsZero():
+		return x, nil
+	case y.IsZero():
+		return y, nil
+	}
+	xm, xn := x.abs()
+	ym, yn := y.abs()
+	hi, lo := bits.Mul64(xm, ym)
+	e := x.exponent + y.exponent
+	g := guard{sbit: xn != yn}
+	for hi != 0 || lo > numberMaxMantissa {
+		var d uint64
+		hi, lo, d = divu10(hi, lo)
+		g.push(d)
+		e++
+	}
+	m := lo
+	if r := g.round(mode); r == 1 || (r == 0 && m&1 == 1) {
+		m++
+		if m > numberMaxMantissa {
+			m /= 10
+			e++
+		}
+	}
+	if e < numberMinExponent {
+		return zeroNumber, nil
+	}
+	if e > numberMaxExponent {
+		return zeroNumber, fmt.Errorf("Number multiplication overflow: %d", e)
+	}
+	return signedNumber(xn != yn, m, e), nil
+}
+
+// Div returns x/y. The quotient is truncated to 17 or 18 digits before
+// being rounded, as rippled does.
+func (x Number) Div(y Number, mode RoundingMode) (Number, error) {
+	if y.IsZero() {
+		return zeroNumber, fmt.Errorf("Number division by zero")
+	}
+	if x.IsZero() {
+		return x, nil
+	}
+	nm, nn := x.abs()
+	dm, dn := y.abs()
+	hi, lo := bits.Mul64(nm, tenTo17)
+	q, _ := bits.Div64(hi, lo, dm)
+	return newNumber(nn != dn, q, x.exponent-y.exponent-17, mode)
+}
+
+// numberToInteger converts a possibly unnormalised mantissa and exponent
+// to an integer, as rippled's Number::operator rep.
+func numberToInteger(negative bool, m uint64, e int, mode RoundingMode) (uint64, error) {
+	if m == 0 {
+		return 0, nil
+	}
+	g := guard{sbit: negative}
+	for ; e < 0; e++ {
+		g.push(m % 10)
+		m /= 10
+	}
+	for ; e > 0; e-- {
+		if m > math.MaxInt64/10 {
+			return 0, fmt.Errorf("Number overflow converting to integer")
+		}
+		m *= 10
+	}
+	if r := g.round(mode); r == 1 || (r == 0 && m&1 == 1) {
+		m++
+	}
+	return m, nil
+}
+
+// divu10 divides a 128 bit integer by 10 returning the quotient and remainder
+func divu10(hi, lo uint64) (uint64, uint64, uint64) {
+	qhi, r := hi/10, hi%10
+	qlo, r := bits.Div64(r, lo, 10)
+	return qhi, qlo, r
+}
+
+// Number returns v as a Number. XRP are interpreted as drops.
+func (v Value) Number(mode RoundingMode) (Number, error) {
+	if v.native {
+		return newNumber(v.negative, v.num, 0, mode)
+	}
+	return newNumber(v.negative, v.num, int(v.offset), mode)
+}
+
+// Value returns x as a Value. Native Values are rounded to a whole number
+// of drops with mode.
+func (x Number) Value(native bool, mode RoundingMode) (*Value, error) {
+	m, negative := x.abs()
+	return numberValue(native, negative, m, x.exponent, mode)
+}
+
+// numberValue canonicalises an amount the way rippled's STAmount does once
+// fixUniversalNumber is enabled.
+func numberValue(native, negative bool, m uint64, e int, mode RoundingMode) (*Value, error) {
+	if m == 0 {
+		return newValue(native, false, 0, 0).ZeroClone(), nil
+	}
+	if native {
+		if e <= -20 {
+			return zeroNative.Clone(), nil
+		}
+		if e > 17 {
+			return nil, fmt.Errorf("Native amount out of range: %de%d", m, e)
+		}
+		drops, err := numberToInteger(negative, m, e, mode)
+		if err != nil {
+			return nil, err
+		}
+		if drops > maxNative {
+			return nil, fmt.Errorf("Native amount out of range: %d", drops)
+		}
+		if drops == 0 {
+			return zeroNative.Clone(), nil
+		}
+		return newValue(true, negative, drops, 0), nil
+	}
+	n, err := newNumber(negative, m, e, mode)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case n.IsZero() || n.exponent < int(minOffset):
+		return zeroNonNative.Clone(), nil
+	case n.exponent > int(maxOffset):
+		return nil, fmt.Errorf("Value overflow: %s", n)
+	}
+	m, negative = n.abs()
+	return newValue(false, negative, m, int64(n.exponent)), nil
+}
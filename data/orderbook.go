@@ -6,6 +6,9 @@ import (
 	"strings"
 )
 
+// Asset is the string form of an Issue.
+//
+// Deprecated: use Issue, which is faster to compare.
 type Asset struct {
 	Currency string `json:"currency"`
 	Issuer   string `json:"issuer,omitempty"`
@@ -21,8 +24,15 @@ func NewAsset(s string) (*Asset, error) {
 	if len(parts) != 2 {
 		return nil, fmt.Errorf("bad asset: %s", s)
 	}
+	currency, err := NewCurrency(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := currency.CheckIssued(); err != nil {
+		return nil, err
+	}
 	return &Asset{
-		Currency: parts[0],
+		Currency: currency.Machine(),
 		Issuer:   parts[1],
 	}, nil
 }
@@ -32,8 +42,18 @@ func (a *Asset) IsNative() bool {
 }
 
 func (a *Asset) Matches(amount *Amount) bool {
-	return (a.IsNative() && amount.IsNative()) ||
-		(a.Currency == amount.Currency.String() && a.Issuer == amount.Issuer.String())
+	if a.IsNative() || amount.IsNative() {
+		return a.IsNative() && amount.IsNative()
+	}
+	// Compare machine forms as display forms, such as demurrage rates, do
+	// not parse and need not be unique
+	currency, err := NewCurrency(a.Currency)
+	return err == nil && currency.Machine() == amount.Currency.Machine() && a.Issuer == amount.Issuer.String()
+}
+
+// Issue returns the typed form of a
+func (a Asset) Issue() (*Issue, error) {
+	return NewIssue(a.String())
 }
 
 func (a Asset) String() string {
@@ -66,11 +86,10 @@ func (s AccountOfferSlice) Len() int           { return len(s) }
 func (s AccountOfferSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 func (s AccountOfferSlice) Less(i, j int) bool { return s[i].Sequence > s[j].Sequence }
 
-func (s AccountOfferSlice) GetSequences(pays, gets *Asset) []uint32 {
-	// TODO: improve performance
+func (s AccountOfferSlice) GetSequences(pays, gets Issue) []uint32 {
 	var sequences []uint32
 	for i := range s {
-		if pays.Matches(&s[i].TakerPays) && gets.Matches(&s[i].TakerGets) {
+		if s[i].TakerPays.Issue() == pays && s[i].TakerGets.Issue() == gets {
 			sequences = append(sequences, s[i].Sequence)
 		}
 	}
@@ -158,9 +177,16 @@ type AccountLine struct {
 	QualityOut     uint32         `json:"quality_out"`
 }
 
+func (l *AccountLine) Issue() Issue {
+	return Issue{
+		Currency: l.Currency,
+		Issuer:   l.Account,
+	}
+}
+
 func (l *AccountLine) Asset() *Asset {
 	return &Asset{
-		Currency: l.Currency.String(),
+		Currency: l.Currency.Machine(),
 		Issuer:   l.Account.String(),
 	}
 }
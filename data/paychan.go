@@ -0,0 +1,41 @@
+package data
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/rubblelabs/ripple/crypto"
+)
+
+// A claim authorizes the destination of a payment channel to take amount
+// XRP in total from it. The source signs the claim off ledger with the key
+// of the channel and the destination submits it in a PaymentChannelClaim.
+func claimMessage(channel Hash256, amount Value) ([]byte, error) {
+	if !amount.IsNative() || amount.IsNegative() {
+		return nil, fmt.Errorf("Bad claim amount: %s", amount.String())
+	}
+	msg := make([]byte, 0, 4+len(channel)+8)
+	msg = append(msg, HP_PAYMENT_CHANNEL_CLAIM.Bytes()...)
+	msg = append(msg, channel[:]...)
+	return binary.BigEndian.AppendUint64(msg, amount.num), nil
+}
+
+// SignClaim returns the signature of a claim for amount drops from channel
+// by the channel key publicKey held by signer
+func SignClaim(channel Hash256, amount Value, signer crypto.Signer, publicKey []byte) (VariableLength, error) {
+	msg, err := claimMessage(channel, amount)
+	if err != nil {
+		return nil, err
+	}
+	return sign(signer, publicKey, crypto.Sha512Half(msg), msg)
+}
+
+// VerifyClaim returns true if signature is that of the channel key
+// publicKey for a claim of amount drops from channel
+func VerifyClaim(channel Hash256, amount Value, publicKey PublicKey, signature VariableLength) (bool, error) {
+	msg, err := claimMessage(channel, amount)
+	if err != nil {
+		return false, err
+	}
+	return crypto.Verify(publicKey.Bytes(), crypto.Sha512Half(msg), msg, signature)
+}
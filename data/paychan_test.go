@@ -0,0 +1,66 @@
+package data
+
+import (
+	"github.com/rubblelabs/ripple/crypto"
+	. "gopkg.in/check.v1"
+)
+
+type PayChanSuite struct{}
+
+var _ = Suite(&PayChanSuite{})
+
+const claimChannel = "5DB01B7FFED6B67E6B0414DED11E051D2EE2B7619CE0EAA6286D67A3A4D5BDB3"
+
+func (s *PayChanSuite) TestClaimMessage(c *C) {
+	channel, err := NewHash256(claimChannel)
+	c.Assert(err, IsNil)
+	msg, err := claimMessage(*channel, *amountCheck("1/XRP").Value)
+	c.Assert(err, IsNil)
+	c.Check(string(b2h(msg)), Equals, "434C4D00"+claimChannel+"00000000000F4240")
+	_, err = claimMessage(*channel, *amountCheck("1/USD/" + testIssuer).Value)
+	c.Check(err, ErrorMatches, "Bad claim amount: 1")
+	_, err = claimMessage(*channel, *amountCheck("-1/XRP").Value)
+	c.Check(err, ErrorMatches, "Bad claim amount: -1")
+}
+
+func (s *PayChanSuite) TestSignClaim(c *C) {
+	channel, err := NewHash256(claimChannel)
+	c.Assert(err, IsNil)
+	seed, _, err := NewSeedFromAddress("snoPBrXtMeMyMHUVTgbuqAfg1SUTb")
+	c.Assert(err, IsNil)
+	amount, other := *amountCheck("1/XRP").Value, *amountCheck("1.000001/XRP").Value
+	for _, signer := range []*crypto.KeySigner{
+		crypto.NewKeySigner(seed.Key(Ed25519), nil),
+		crypto.NewKeySigner(seed.Key(ECDSA), new(uint32)),
+	} {
+		var publicKey PublicKey
+		copy(publicKey[:], signer.Public())
+		sig, err := SignClaim(*channel, amount, signer, publicKey.Bytes())
+		c.Assert(err, IsNil)
+		ok, err := VerifyClaim(*channel, amount, publicKey, sig)
+		c.Check(err, IsNil)
+		c.Check(ok, Equals, true)
+		ok, err = VerifyClaim(*channel, other, publicKey, sig)
+		c.Check(err, IsNil)
+		c.Check(ok, Equals, false)
+	}
+}
+
+// The channel_authorize example of the XRPL documentation: a claim of 1 XRP
+// signed by the key aB44YfzW24VDEJQ2UuLPV2PvqcPCSoLnL7y5M1EzhdW4LnK5xMS3
+func (s *PayChanSuite) TestPublishedClaim(c *C) {
+	channel, err := NewHash256(claimChannel)
+	c.Assert(err, IsNil)
+	key, err := crypto.NewRippleHashCheck("aB44YfzW24VDEJQ2UuLPV2PvqcPCSoLnL7y5M1EzhdW4LnK5xMS3", crypto.RIPPLE_ACCOUNT_PUBLIC)
+	c.Assert(err, IsNil)
+	var publicKey PublicKey
+	copy(publicKey[:], key.Payload())
+	var sig VariableLength
+	c.Assert(sig.UnmarshalText([]byte("304402204EF0AFB78AC23ED1C472E74F4299C0C21F1B21D07EFC0A3838A420F76D783A400220154FB11B6F54320666E4C36CA7F686C16A3A0456800BBC43746F34AF50290064")), IsNil)
+	ok, err := VerifyClaim(*channel, *amountCheck("1/XRP").Value, publicKey, sig)
+	c.Check(err, IsNil)
+	c.Check(ok, Equals, true)
+	ok, err = VerifyClaim(*channel, *amountCheck("1.000001/XRP").Value, publicKey, sig)
+	c.Check(err, IsNil)
+	c.Check(ok, Equals, false)
+}
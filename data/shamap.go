@@ -0,0 +1,352 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// ShaMap is an in-memory radix tree of the kind used for a ledger's
+// transaction and account state trees. Each level of the tree consumes
+// one 4 bit nibble of an item's index, and an item is held at the
+// shallowest depth at which its index is unique. The root hash of a
+// ShaMap built from all the items in a ledger equals the LedgerHeader's
+// TransactionHash or StateHash.
+type ShaMap struct {
+	typ  NodeType
+	root *shaMapInner
+}
+
+type shaMapNode interface {
+	hash() (Hash256, error)
+}
+
+type shaMapLeaf struct {
+	index  Hash256
+	nodeId Hash256
+}
+
+type shaMapInner struct {
+	depth    int
+	children [16]shaMapNode
+}
+
+// NodeFetcher returns the node with the supplied node id, for example from
+// a nodestore or by asking a peer. Inner nodes must be returned as *InnerNode.
+type NodeFetcher func(nodeId Hash256) (Storer, error)
+
+// Proof is a Merkle proof that an item with the index Key is included in a
+// tree. Path holds the inner nodes from the root down to the parent of the
+// item's leaf node.
+type Proof struct {
+	Key  Hash256
+	Path []InnerNode
+}
+
+// NewShaMap returns an empty tree for either transactions (NT_TRANSACTION_NODE)
+// or account state (NT_ACCOUNT_NODE).
+func NewShaMap(typ NodeType) *ShaMap {
+	return &ShaMap{
+		typ:  typ,
+		root: &shaMapInner{},
+	}
+}
+
+// NewTransactionMap builds the transaction tree for a ledger
+func NewTransactionMap(txs TransactionSlice) (*ShaMap, error) {
+	m := NewShaMap(NT_TRANSACTION_NODE)
+	for _, txm := range txs {
+		if err := m.AddItem(txm); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// NewStateMap builds the account state tree for a ledger
+func NewStateMap(les LedgerEntrySlice) (*ShaMap, error) {
+	m := NewShaMap(NT_ACCOUNT_NODE)
+	for _, le := range les {
+		if err := m.AddItem(le); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// nibble returns the 4 bit value of index at the given depth
+func nibble(index Hash256, depth int) int {
+	b := index[depth/2]
+	if depth%2 == 0 {
+		return int(b >> 4)
+	}
+	return int(b & 0x0F)
+}
+
+// LeafIndex returns the index which positions a
+// TransactionWithMetaData or LedgerEntry in a tree.
+func LeafIndex(s Storer) (*Hash256, error) {
+	switch v := s.(type) {
+	case *TransactionWithMetaData:
+		return v.GetHash(), nil
+	case LedgerEntry:
+		if index := v.GetLedgerIndex(); index != nil {
+			return index, nil
+		}
+		if !v.GetHash().IsZero() {
+			// ReadLedgerEntry stores the index as the hash
+			return v.GetHash(), nil
+		}
+		return LedgerIndex(v)
+	default:
+		return nil, fmt.Errorf("No leaf index for: %s", s.GetType())
+	}
+}
+
+// AddItem computes the index and node id of a TransactionWithMetaData
+// or LedgerEntry and adds it to the tree.
+func (m *ShaMap) AddItem(s Storer) error {
+	index, err := LeafIndex(s)
+	if err != nil {
+		return err
+	}
+	nodeId, err := NodeId(s)
+	if err != nil {
+		return err
+	}
+	return m.Add(*index, nodeId)
+}
+
+// Add inserts or replaces the leaf node with the given index and node id
+func (m *ShaMap) Add(index, nodeId Hash256) error {
+	leaf := &shaMapLeaf{index: index, nodeId: nodeId}
+	for inner := m.root; ; {
+		pos := nibble(index, inner.depth)
+		switch child := inner.children[pos].(type) {
+		case nil:
+			inner.children[pos] = leaf
+			return nil
+		case *shaMapInner:
+			inner = child
+		case *shaMapLeaf:
+			if child.index == index {
+				inner.children[pos] = leaf
+				return nil
+			}
+			if inner.depth+1 >= 64 {
+				return fmt.Errorf("ShaMap: depth exceeded for: %s", index)
+			}
+			// Push the existing leaf down a level and try again
+			next := &shaMapInner{depth: inner.depth + 1}
+			next.children[nibble(child.index, next.depth)] = child
+			inner.children[pos] = next
+			inner = next
+		}
+	}
+}
+
+// Hash returns the root hash of the tree. An empty tree has a zero hash.
+func (m *ShaMap) Hash() (Hash256, error) {
+	return m.root.hash()
+}
+
+// Proof returns the inclusion proof for the item with the supplied index.
+func (m *ShaMap) Proof(index Hash256) (*Proof, error) {
+	proof := &Proof{Key: index}
+	for inner := m.root; ; {
+		node, err := inner.innerNode(m.typ)
+		if err != nil {
+			return nil, err
+		}
+		proof.Path = append(proof.Path, *node)
+		switch child := inner.children[nibble(index, inner.depth)].(type) {
+		case *shaMapInner:
+			inner = child
+		case *shaMapLeaf:
+			if child.index != index {
+				return nil, fmt.Errorf("ShaMap: %s not found", index)
+			}
+			return proof, nil
+		default:
+			return nil, fmt.Errorf("ShaMap: %s not found", index)
+		}
+	}
+}
+
+func (l *shaMapLeaf) hash() (Hash256, error) {
+	return l.nodeId, nil
+}
+
+func (n *shaMapInner) innerNode(typ NodeType) (*InnerNode, error) {
+	inner := &InnerNode{Type: typ}
+	for i, child := range n.children {
+		if child == nil {
+			continue
+		}
+		hash, err := child.hash()
+		if err != nil {
+			return nil, err
+		}
+		inner.Children[i] = hash
+	}
+	id, err := NodeId(inner)
+	if err != nil {
+		return nil, err
+	}
+	inner.Id = id
+	return inner, nil
+}
+
+func (n *shaMapInner) hash() (Hash256, error) {
+	if n.isEmpty() {
+		return zero256, nil
+	}
+	inner, err := n.innerNode(NT_UNKNOWN)
+	if err != nil {
+		return zero256, err
+	}
+	return inner.Id, nil
+}
+
+func (n *shaMapInner) isEmpty() bool {
+	for _, child := range n.children {
+		if child != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// NewProof walks a tree stored elsewhere, starting at root and following the
+// nibbles of index, fetching each node with fetch.
+func NewProof(root, index Hash256, fetch NodeFetcher) (*Proof, error) {
+	proof := &Proof{Key: index}
+	for nodeId := root; len(proof.Path) < 64; {
+		node, err := fetch(nodeId)
+		if err != nil {
+			return nil, err
+		}
+		inner, ok := node.(*InnerNode)
+		if !ok {
+			leafIndex, err := LeafIndex(node)
+			if err != nil {
+				return nil, err
+			}
+			if *leafIndex != index {
+				return nil, fmt.Errorf("Proof: %s not found", index)
+			}
+			return proof, nil
+		}
+		proof.Path = append(proof.Path, *inner)
+		nodeId = inner.Children[nibble(index, len(proof.Path)-1)]
+		if nodeId.IsZero() {
+			return nil, fmt.Errorf("Proof: %s not found", index)
+		}
+	}
+	return nil, fmt.Errorf("Proof: depth exceeded for: %s", index)
+}
+
+// Verify checks that the node id of a leaf is reachable from root by
+// following the nibbles of the proof's Key.
+func (p *Proof) Verify(root, leaf Hash256) error {
+	if len(p.Path) == 0 {
+		return fmt.Errorf("Proof: empty path")
+	}
+	if len(p.Path) > 64 {
+		return fmt.Errorf("Proof: path too long: %d", len(p.Path))
+	}
+	expected := root
+	for depth := range p.Path {
+		inner := &p.Path[depth]
+		hash, err := NodeId(inner)
+		if err != nil {
+			return err
+		}
+		if hash != expected {
+			return fmt.Errorf("Proof: bad inner node at depth %d: %s expected: %s", depth, hash, expected)
+		}
+		expected = inner.Children[nibble(p.Key, depth)]
+	}
+	if expected != leaf {
+		return fmt.Errorf("Proof: bad leaf: %s expected: %s", leaf, expected)
+	}
+	return nil
+}
+
+// VerifyTransaction checks that txm is included in the transaction tree of a ledger
+func (p *Proof) VerifyTransaction(header *LedgerHeader, txm *TransactionWithMetaData) error {
+	return p.verifyItem(header.TransactionHash, txm)
+}
+
+// VerifyLedgerEntry checks that le is included in the account state tree of a ledger
+func (p *Proof) VerifyLedgerEntry(header *LedgerHeader, le LedgerEntry) error {
+	return p.verifyItem(header.StateHash, le)
+}
+
+func (p *Proof) verifyItem(root Hash256, s Storer) error {
+	index, err := LeafIndex(s)
+	if err != nil {
+		return err
+	}
+	if *index != p.Key {
+		return fmt.Errorf("Proof: index %s does not match key: %s", index, p.Key)
+	}
+	// The node id is recomputed so that a forged Id field is ignored
+	nodeId, err := NodeId(s)
+	if err != nil {
+		return err
+	}
+	return p.Verify(root, nodeId)
+}
+
+// Marshal writes the key followed by each inner node in the compressed
+// wire format, prefixed by its variable length.
+func (p *Proof) Marshal(w io.Writer) error {
+	if err := p.Key.Marshal(w); err != nil {
+		return err
+	}
+	for i := range p.Path {
+		var buf bytes.Buffer
+		if err := p.Path[i].Each(func(pos int, child Hash256) error {
+			return write(&buf, CompressedNodeEntry{Hash: child, Pos: uint8(pos)})
+		}); err != nil {
+			return err
+		}
+		if err := writeVariableLength(w, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Proof) Unmarshal(r Reader) error {
+	if err := p.Key.Unmarshal(r); err != nil {
+		return err
+	}
+	p.Path = nil
+	for r.Len() > 0 {
+		lr, err := NewVariableByteReader(r)
+		if err != nil {
+			return err
+		}
+		inner, err := readCompressedInnerNode(lr, NT_UNKNOWN, zero256)
+		if err != nil {
+			return err
+		}
+		if inner.Id, err = NodeId(inner); err != nil {
+			return err
+		}
+		p.Path = append(p.Path, *inner)
+	}
+	return nil
+}
+
+func (p Proof) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	err := p.Marshal(&buf)
+	return buf.Bytes(), err
+}
+
+func (p *Proof) UnmarshalBinary(b []byte) error {
+	return p.Unmarshal(bytes.NewReader(b))
+}
@@ -0,0 +1,95 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+type ShaMapSuite struct{}
+
+var _ = Suite(&ShaMapSuite{})
+
+func loadLedger(c *C, filename string) *Ledger {
+	b, err := ioutil.ReadFile(filename)
+	c.Assert(err, IsNil)
+	var ledger Ledger
+	c.Assert(json.Unmarshal(b, &ledger), IsNil)
+	return &ledger
+}
+
+func (s *ShaMapSuite) TestLedgerTrees(c *C) {
+	ledger := loadLedger(c, "testdata/ledger_6000000.json")
+	state, err := NewStateMap(ledger.AccountState)
+	c.Assert(err, IsNil)
+	stateHash, err := state.Hash()
+	c.Assert(err, IsNil)
+	c.Check(stateHash.String(), Equals, ledger.StateHash.String())
+	txs, err := NewTransactionMap(ledger.Transactions)
+	c.Assert(err, IsNil)
+	txHash, err := txs.Hash()
+	c.Assert(err, IsNil)
+	c.Check(txHash.String(), Equals, ledger.TransactionHash.String())
+}
+
+func (s *ShaMapSuite) TestProofs(c *C) {
+	ledger := loadLedger(c, "testdata/ledger_6000000.json")
+	state, err := NewStateMap(ledger.AccountState)
+	c.Assert(err, IsNil)
+	for _, le := range ledger.AccountState {
+		proof, err := state.Proof(*le.GetLedgerIndex())
+		c.Assert(err, IsNil)
+		c.Check(proof.VerifyLedgerEntry(&ledger.LedgerHeader, le), IsNil)
+		b, err := proof.MarshalBinary()
+		c.Assert(err, IsNil)
+		var decoded Proof
+		c.Assert(decoded.UnmarshalBinary(b), IsNil)
+		c.Check(decoded.VerifyLedgerEntry(&ledger.LedgerHeader, le), IsNil)
+	}
+	txs, err := NewTransactionMap(ledger.Transactions)
+	c.Assert(err, IsNil)
+	txm := ledger.Transactions[0]
+	proof, err := txs.Proof(*txm.GetHash())
+	c.Assert(err, IsNil)
+	c.Check(proof.VerifyTransaction(&ledger.LedgerHeader, txm), IsNil)
+	c.Check(proof.VerifyLedgerEntry(&ledger.LedgerHeader, ledger.AccountState[0]), ErrorMatches, "Proof: index .* does not match key: .*")
+
+	// Tampering with the leaf must be detected
+	root := ledger.AccountState[0].(*AccountRoot)
+	proof, err = state.Proof(*root.LedgerIndex)
+	c.Assert(err, IsNil)
+	*root.Sequence++
+	c.Check(proof.VerifyLedgerEntry(&ledger.LedgerHeader, root), ErrorMatches, "Proof: bad leaf: .*")
+
+	_, err = state.Proof(zero256)
+	c.Check(err, ErrorMatches, "ShaMap: .* not found")
+}
+
+func (s *ShaMapSuite) TestProofFromNodes(c *C) {
+	ledger := loadLedger(c, "testdata/ledger_6000000.json")
+	state, err := NewStateMap(ledger.AccountState)
+	c.Assert(err, IsNil)
+	nodes := make(map[Hash256]Storer)
+	for _, le := range ledger.AccountState {
+		proof, err := state.Proof(*le.GetLedgerIndex())
+		c.Assert(err, IsNil)
+		for i := range proof.Path {
+			nodes[proof.Path[i].Id] = &proof.Path[i]
+		}
+		nodeId, err := NodeId(le)
+		c.Assert(err, IsNil)
+		nodes[nodeId] = le
+	}
+	fetch := func(nodeId Hash256) (Storer, error) {
+		if node, ok := nodes[nodeId]; ok {
+			return node, nil
+		}
+		return nil, fmt.Errorf("missing node: %s", nodeId)
+	}
+	le := ledger.AccountState[42]
+	proof, err := NewProof(ledger.StateHash, *le.GetLedgerIndex(), fetch)
+	c.Assert(err, IsNil)
+	c.Check(proof.VerifyLedgerEntry(&ledger.LedgerHeader, le), IsNil)
+}
@@ -7,14 +7,16 @@ import (
 	"github.com/rubblelabs/ripple/crypto"
 )
 
-func Sign(s Signable, key crypto.Key, sequence *uint32) error {
+// Sign signs s with the key of signer identified by publicKey. Use
+// crypto.NewKeySigner for a key held in memory.
+func Sign(s Signable, signer crypto.Signer, publicKey []byte) error {
 	s.InitialiseForSigning()
-	copy(s.GetPublicKey().Bytes(), key.Public(sequence))
+	copy(s.GetPublicKey().Bytes(), publicKey)
 	hash, msg, err := SigningHash(s)
 	if err != nil {
 		return err
 	}
-	sig, err := crypto.Sign(key.Private(sequence), hash.Bytes(), append(s.SigningPrefix().Bytes(), msg...))
+	sig, err := sign(signer, publicKey, hash.Bytes(), append(s.SigningPrefix().Bytes(), msg...))
 	if err != nil {
 		return err
 	}
@@ -27,15 +29,26 @@ func Sign(s Signable, key crypto.Key, sequence *uint32) error {
 	return nil
 }
 
+func sign(signer crypto.Signer, publicKey, hash, msg []byte) (VariableLength, error) {
+	payload, err := crypto.SigningPayload(publicKey, hash, msg)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Sign(publicKey, payload)
+}
+
 func CheckSignature(s Signable) (bool, error) {
 	hash, msg, err := SigningHash(s)
 	if err != nil {
 		return false, err
 	}
+	msg = append(s.SigningPrefix().Bytes(), msg...)
 	return crypto.Verify(s.GetPublicKey().Bytes(), hash.Bytes(), msg, s.GetSignature().Bytes())
 }
 
-func MultiSign(s MultiSignable, key crypto.Key, sequence *uint32, account Account) error {
+// MultiSign signs s for account with the key of signer identified by
+// publicKey
+func MultiSign(s MultiSignable, signer crypto.Signer, publicKey []byte, account Account) error {
 	s.InitialiseForSigning()
 	hash, msg, err := MultiSigningHash(s, account)
 	if err != nil {
@@ -44,13 +57,13 @@ func MultiSign(s MultiSignable, key crypto.Key, sequence *uint32, account Accoun
 	msg = append(s.MultiSigningPrefix().Bytes(), msg...)
 	msg = append(msg, account.Bytes()...)
 
-	sig, err := crypto.Sign(key.Private(sequence), hash.Bytes(), msg)
+	sig, err := sign(signer, publicKey, hash.Bytes(), msg)
 	if err != nil {
 		return err
 	}
 	*s.GetSignature() = sig
 	// copy pub key only after the signing
-	copy(s.GetPublicKey().Bytes(), key.Public(sequence))
+	copy(s.GetPublicKey().Bytes(), publicKey)
 
 	return nil
 }
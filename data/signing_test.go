@@ -19,7 +19,7 @@ func TestMultiSignWithVerification(t *testing.T) {
 
 	// prepare first signature
 	tx := buildPaymentTxForTheMultiSigning(t)
-	if err := MultiSign(tx, key1, &seq, account1); err != nil {
+	if err := MultiSign(tx, crypto.NewKeySigner(key1, &seq), key1.Public(&seq), account1); err != nil {
 		t.Fatal(err)
 	}
 	signer1 := Signer{
@@ -31,7 +31,7 @@ func TestMultiSignWithVerification(t *testing.T) {
 	}
 	// prepare second signature
 	tx = buildPaymentTxForTheMultiSigning(t)
-	if err := MultiSign(tx, key2, &seq, account2); err != nil {
+	if err := MultiSign(tx, crypto.NewKeySigner(key2, &seq), key2.Public(&seq), account2); err != nil {
 		t.Fatal(err)
 	}
 	signer2 := Signer{
@@ -111,10 +111,53 @@ func genSeedFromPassword(t *testing.T, password string) *Seed {
 	if err != nil {
 		t.Fatal(err)
 	}
-	seed, err := NewSeedFromAddress(seedFromPass.String())
+	seed, _, err := NewSeedFromAddress(seedFromPass.String())
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	return seed
 }
+
+func TestSignWithSignerFunc(t *testing.T) {
+	seed := genSeedFromPassword(t, "password1")
+	key := seed.Key(Ed25519)
+	var signed []byte
+	// stands in for a signer holding the key out of process
+	signer := crypto.SignerFunc(func(publicKey, payload []byte) ([]byte, error) {
+		signed = payload
+		return crypto.NewKeySigner(key, nil).Sign(publicKey, payload)
+	})
+	tx := buildPaymentTxForTheMultiSigning(t)
+	if err := Sign(tx, signer, key.Public(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, msg, _ := SigningHash(tx); !reflect.DeepEqual(signed, append(tx.SigningPrefix().Bytes(), msg...)) {
+		t.Fatalf("Unexpected payload: %X", signed)
+	}
+	if valid, err := CheckSignature(tx); err != nil || !valid {
+		t.Fatalf("Unexpected invalid signature, err:%v", err)
+	}
+}
+
+func TestSignWithBIP44Key(t *testing.T) {
+	seed, err := crypto.MnemonicSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := crypto.NewBIP44Key(seed, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := buildPaymentTxForTheMultiSigning(t)
+	copy(tx.Account[:], key.Id(nil))
+	if err := Sign(tx, crypto.NewKeySigner(key, nil), key.Public(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if tx.Account.String() != "rHsMGQEkVNJmpGWs8XUBoTBiAAbwxZN5v3" {
+		t.Fatalf("Unexpected account: %s", tx.Account)
+	}
+	if valid, err := CheckSignature(tx); err != nil || !valid {
+		t.Fatalf("Unexpected invalid signature, err:%v", err)
+	}
+}
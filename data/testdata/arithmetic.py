@@ -0,0 +1,303 @@
+# Generates arithmetic.txt: python3 arithmetic.py > arithmetic.txt
+#
+# This is a port of rippled's arithmetic written against Python's exact
+# integers and fractions rather than a derivation from first principles.
+# The legacy rows follow STAmount's multiply, divide, mulRound and divRound,
+# including their truncation and the +7 and +5 fudge of multiply and divide,
+# so they differ from the correctly rounded results. The universal rows
+# follow Number: sums and products are rounded exactly to 16 digits, but
+# quotients are truncated to 17 digits first, as Number::operator/ does, so
+# an inexact quotient can round as a tie. None of the rows were checked
+# against rippled; arithmetic_test.go holds the cases taken from rippled.
+import json, random
+from fractions import Fraction
+from math import floor, ceil
+
+MINM, MAXM = 10**15, 10**16 - 1
+MINOFF, MAXOFF = -96, 80
+
+def val(native, neg, m, e):
+    return dict(native=native, neg=neg, m=m, e=e)
+
+def frac(v):
+    f = Fraction(v['m']) * (Fraction(10) ** v['e'])
+    return -f if v['neg'] else f
+
+def fmt(v):
+    if v is None:
+        return "error"
+    if v['m'] == 0:
+        return "0"
+    s = "-" if v['neg'] else ""
+    if v['native']:
+        return s + str(v['m'])
+    return "%s%de%d" % (s, v['m'], v['e'])
+
+def rnd_int(f, mode):
+    # round rational f to integer with mode
+    fl = floor(f)
+    rem = f - fl
+    if rem == 0:
+        return fl
+    if mode == 'up':
+        return fl + 1
+    if mode == 'down':
+        return fl
+    if mode == 'zero':
+        return fl if f >= 0 else fl + 1
+    # nearest even
+    if rem > Fraction(1, 2):
+        return fl + 1
+    if rem < Fraction(1, 2):
+        return fl
+    return fl if fl % 2 == 0 else fl + 1
+
+def round16(f, mode):
+    # round nonzero rational to 16 significant digits; returns (neg, m, e)
+    if f == 0:
+        return (False, 0, 0)
+    neg = f < 0
+    a = -f if neg else f
+    e = 0
+    while a * Fraction(10) ** (-e) >= 10**16:
+        e += 1
+    while a * Fraction(10) ** (-e) < 10**15:
+        e -= 1
+    scaled = f * Fraction(10) ** (-e)
+    m = abs(rnd_int(scaled, mode))
+    if m > MAXM:
+        m //= 10
+        e += 1
+    return (neg, m, e)
+
+def iou(neg, m, e):
+    if m == 0 or e < MINOFF:
+        return val(False, False, 0, -100)
+    if e > MAXOFF:
+        return None
+    return val(False, neg, m, e)
+
+def drops(neg, d):
+    if d == 0:
+        return val(True, False, 0, 0)
+    if d > 9 * 10**18:
+        return None
+    return val(True, neg, d, 0)
+
+def mant(v):
+    m, e = v['m'], v['e']
+    if v['native']:
+        while m < MINM:
+            m *= 10
+            e -= 1
+    return m, e
+
+# --- Number (fixUniversalNumber), correctly rounded semantics ---
+
+def number_round(f, mode):
+    return round16(f, mode)
+
+def number_value(neg, m, e, native, mode):
+    if m == 0:
+        return val(native, False, 0, 0 if native else -100)
+    f = Fraction(m) * Fraction(10) ** e
+    if neg:
+        f = -f
+    if native:
+        if e <= -20:
+            return val(True, False, 0, 0)
+        d = rnd_int(f, mode)
+        return drops(d < 0, abs(d))
+    n, mm, ee = round16(f, mode)
+    return iou(n, mm, ee)
+
+def to_number(v):
+    # Value -> Number, nearest (exact for the corpus)
+    return round16(frac(v), 'nearest') if v['m'] else (False, 0, 0)
+
+def num_frac(n):
+    neg, m, e = n
+    f = Fraction(m) * Fraction(10) ** e
+    return -f if neg else f
+
+def number_mul(a, b, native):
+    x, y = to_number(a), to_number(b)
+    z = round16(num_frac(x) * num_frac(y), 'nearest')
+    return number_value(z[0], z[1], z[2], native, 'nearest')
+
+def number_div(a, b, native):
+    x, y = to_number(a), to_number(b)
+    # truncate to 17 digits before rounding, not the exact quotient
+    q = (x[1] * 10**17) // y[1]
+    neg = x[0] != y[0]
+    f = Fraction(q) * Fraction(10) ** (x[2] - y[2] - 17)
+    z = round16(-f if neg else f, 'nearest')
+    return number_value(z[0], z[1], z[2], native, 'nearest')
+
+def number_add(a, b):
+    f = frac(a) + frac(b)
+    if f == 0:
+        return val(False, False, 0, -100)
+    z = round16(f, 'nearest')
+    return iou(*z)
+
+# --- legacy STAmount ---
+
+def legacy_add(a, b):
+    av = -a['m'] if a['neg'] else a['m']
+    bv = -b['m'] if b['neg'] else b['m']
+    ao, bo = a['e'], b['e']
+    def tdiv(x):
+        return -((-x) // 10) if x < 0 else x // 10
+    while ao < bo:
+        av = tdiv(av); ao += 1
+    while bo < ao:
+        bv = tdiv(bv); bo += 1
+    s = av + bv
+    if -10 <= s <= 10:
+        return val(False, False, 0, -100)
+    return legacy_construct(False, s < 0, abs(s), ao)
+
+def legacy_construct(native, neg, m, e):
+    if native:
+        if m == 0 or e <= -20:
+            return val(True, False, 0, 0)
+        if e < 0:
+            m = m // 10**(-e)
+        else:
+            m = m * 10**e
+        return drops(neg, m)
+    if m == 0:
+        return val(False, False, 0, -100)
+    while m < MINM and e > MINOFF:
+        m *= 10; e -= 1
+    while m > MAXM:
+        if e >= MAXOFF:
+            return None
+        m //= 10; e += 1
+    if e < MINOFF or m < MINM:
+        return val(False, False, 0, -100)
+    if e > MAXOFF:
+        return None
+    return val(False, neg, m, e)
+
+def legacy_mul(a, b, native):
+    am, ae = mant(a); bm, be = mant(b)
+    if am * bm // 10**14 >= 2**64:
+        return None
+    return legacy_construct(native, a['neg'] != b['neg'], am * bm // 10**14 + 7, ae + be + 14)
+
+def legacy_div(a, b, native):
+    am, ae = mant(a); bm, be = mant(b)
+    return legacy_construct(native, a['neg'] != b['neg'], am * 10**17 // bm + 5, ae - be - 17)
+
+# --- directed rounding ---
+
+def round_op(exact_mag, scale_e, neg, roundUp, native, strict, universal):
+    # exact_mag: Fraction magnitude at 10**scale_e (i.e. mulDiv quotient before truncation)
+    away = neg != roundUp
+    m = ceil(exact_mag) if away else floor(exact_mag)
+    e = scale_e
+    if away:
+        if native and e < 0:
+            # rippled canonicalizeRound on the integer quotient
+            loops, rem = 0, False
+            while e < -1:
+                rem = rem or m % 10 != 0
+                m //= 10; e += 1; loops += 1
+            if strict:
+                m += 10 if (rem and roundUp) else 9
+            else:
+                m += 9 if loops >= 2 else 10
+            m //= 10; e += 1
+        elif not native and m > MAXM:
+            # rippled truncates all but the last digit removed
+            while m > 10 * MAXM:
+                m //= 10; e += 1
+            m = (m + 9) // 10
+            e += 1
+    mode = 'zero' if strict else 'nearest'
+    if universal:
+        r = number_value(neg, m, e, native, mode)
+    else:
+        r = legacy_construct(native, neg, m, e)
+    if r is not None and roundUp and not neg and r['m'] == 0:
+        return val(True, False, 1, 0) if native else val(False, False, MINM, MINOFF)
+    return r
+
+def mul_round(a, b, native, roundUp, strict, universal):
+    if native and a['native'] and b['native']:
+        return drops(a['neg'] != b['neg'], a['m'] * b['m'])
+    am, ae = mant(a); bm, be = mant(b)
+    if (am * bm + 10**14 - 1) // 10**14 >= 2**64:
+        # rippled's muldiv throws
+        return None
+    return round_op(Fraction(am * bm, 10**14), ae + be + 14, a['neg'] != b['neg'], roundUp, native, strict, universal)
+
+def div_round(a, b, native, roundUp, strict, universal):
+    am, ae = mant(a); bm, be = mant(b)
+    return round_op(Fraction(am * 10**17, bm), ae - be - 17, a['neg'] != b['neg'], roundUp, native, strict, universal)
+
+def rand_value(r, native=None, allow_neg=True):
+    if native is None:
+        native = r.random() < 0.3
+    neg = allow_neg and r.random() < 0.2
+    if native:
+        d = r.choice([r.randint(1, 9), r.randint(1, 10**6), r.randint(1, 10**12), r.randint(10**12, 10**17)])
+        return val(True, neg, d, 0)
+    m = r.randint(MINM, MAXM)
+    if r.random() < 0.2:
+        m = r.choice([MINM, MAXM, 5 * 10**15, 1234567890123450, 2 * 10**15 + 5])
+    e = r.randint(-40, 10)
+    return val(False, neg, m, e)
+
+def main():
+    r = random.Random(20240601)
+    out = []
+    def add_case(op, universal, native, roundUp, a, b, res):
+        out.append(dict(op=op, universal=universal, native=native, roundUp=roundUp, a=fmt_in(a), b=fmt_in(b), expected=fmt(res)))
+    for i in range(5000):
+        universal = r.random() < 0.5
+        op = r.choice(['add', 'mul', 'div', 'mulRound', 'divRound', 'mulRoundStrict', 'divRoundStrict'])
+        native = r.random() < 0.3
+        roundUp = r.random() < 0.5
+        if op == 'add':
+            nat = r.random() < 0.1
+            a = rand_value(r, nat)
+            b = rand_value(r, nat)
+            if not nat and r.random() < 0.5:
+                b['e'] = a['e'] + r.randint(-3, 3)
+            if nat:
+                res = drops(*(lambda s: (s < 0, abs(s)))((-a['m'] if a['neg'] else a['m']) + (-b['m'] if b['neg'] else b['m'])))
+            else:
+                res = number_add(a, b) if universal else legacy_add(a, b)
+            add_case(op, universal, nat, False, a, b, res)
+            continue
+        a = rand_value(r)
+        b = rand_value(r)
+        if op == 'mul':
+            if native and a['native'] and b['native']:
+                continue
+            res = number_mul(a, b, native) if universal else legacy_mul(a, b, native)
+            roundUp = False
+        elif op == 'div':
+            res = number_div(a, b, native) if universal else legacy_div(a, b, native)
+            roundUp = False
+        elif op.startswith('mulRound'):
+            if native and a['native'] and b['native']:
+                continue
+            res = mul_round(a, b, native, roundUp, op.endswith('Strict'), universal)
+        else:
+            res = div_round(a, b, native, roundUp, op.endswith('Strict'), universal)
+        add_case(op, universal, native, roundUp, a, b, res)
+    print("# op universal native roundUp a b expected")
+    for c in out:
+        print(" ".join([c['op'], str(c['universal']).lower(), str(c['native']).lower(), str(c['roundUp']).lower(), c['a'], c['b'], c['expected']]))
+
+def fmt_in(v):
+    s = "-" if v['neg'] else ""
+    if v['native']:
+        return s + str(v['m'])
+    return "%s%de%d/USD" % (s, v['m'], v['e'])
+
+main()
@@ -90,8 +90,8 @@ type AMMCreate struct {
 
 type AMMDeposit struct {
 	TxBase
-	Asset      Asset
-	Asset2     Asset
+	Asset      Issue
+	Asset2     Issue
 	Amount     *Amount `json:",omitempty"`
 	Amount2    *Amount `json:",omitempty"`
 	EPrice     *Amount `json:",omitempty"`
@@ -101,8 +101,8 @@ type AMMDeposit struct {
 
 type AMMWithdraw struct {
 	TxBase
-	Asset     Asset
-	Asset2    Asset
+	Asset     Issue
+	Asset2    Issue
 	Amount    *Amount `json:",omitempty"`
 	Amount2   *Amount `json:",omitempty"`
 	EPrice    *Amount `json:",omitempty"`
@@ -111,15 +111,15 @@ type AMMWithdraw struct {
 
 type AMMVote struct {
 	TxBase
-	Asset      Asset
-	Asset2     Asset
+	Asset      Issue
+	Asset2     Issue
 	TradingFee uint16
 }
 
 type AMMBid struct {
 	TxBase
-	Asset        Asset
-	Asset2       Asset
+	Asset        Issue
+	Asset2       Issue
 	BidMin       *Amount       `json:",omitempty"`
 	BidMax       *Amount       `json:",omitempty"`
 	AuthAccounts []AuthAccount `json:",omitempty"`
@@ -127,8 +127,8 @@ type AMMBid struct {
 
 type AMMDelete struct {
 	TxBase
-	Asset  Asset
-	Asset2 Asset
+	Asset  Issue
+	Asset2 Issue
 }
 
 type TrustSet struct {
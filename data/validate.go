@@ -0,0 +1,726 @@
+package data
+
+// Validation mirrors the preflight checks which rippled makes before a
+// transaction is applied. Only the malformations which can be found
+// without the ledger are reported, with the tem code rippled would return.
+
+const (
+	txUniversalFlags = TxCanonicalSignature | TxInnerBatch
+
+	maxPaths          = 6
+	maxPathLength     = 8
+	maxTicketCount    = 250
+	maxSignerEntries  = 32
+	maxTradingFee     = 1000
+	maxTransferFee    = 50000
+	maxURILength      = 256
+	maxDomainLength   = 256
+	maxOffersToCancel = 500
+	maxAuthAccounts   = 4
+)
+
+// validate makes the checks common to all transactions. flags are those
+// which are valid for the transaction type, ticket its TicketSequence.
+func (t *TxBase) validate(flags TransactionFlag, ticket *uint32) TransactionResult {
+	switch {
+	case t.Account.IsZero():
+		return TemBAD_SRC_ACCOUNT
+	case !t.Fee.IsZero() && (!t.Fee.IsNative() || t.Fee.IsNegative()):
+		return TemBAD_FEE
+	case ticket != nil && t.Sequence != 0:
+		return TemSEQ_AND_TICKET
+	case t.Flags != nil && *t.Flags&^(flags|txUniversalFlags) != 0:
+		return TemINVALID_FLAG
+	case t.Memos.Check() != nil:
+		return TemINVALID
+	default:
+		return TesSUCCESS
+	}
+}
+
+// validatePseudo checks a transaction which can only be created by validators
+func (t *TxBase) validatePseudo() TransactionResult {
+	switch {
+	case !t.Account.IsZero():
+		return TemBAD_SRC_ACCOUNT
+	case !t.Fee.IsZero():
+		return TemBAD_FEE
+	case t.Sequence != 0:
+		return TemBAD_SEQUENCE
+	case (t.SigningPubKey != nil && !t.SigningPubKey.IsZero()) || (t.TxnSignature != nil && len(*t.TxnSignature) > 0):
+		return TemBAD_SIGNATURE
+	default:
+		return TesSUCCESS
+	}
+}
+
+func (t *TxBase) flag(f TransactionFlag) bool {
+	return t.Flags != nil && *t.Flags&f > 0
+}
+
+// Validate checks the fields common to all transactions
+func (t *TxBase) Validate() TransactionResult {
+	return t.validate(0, nil)
+}
+
+func positiveAmount(a *Amount) bool {
+	return a != nil && a.Value != nil && !a.IsZero() && !a.IsNegative()
+}
+
+func badCurrency(a *Amount) bool {
+	return !a.IsNative() && a.Currency.CheckIssued() != nil
+}
+
+// validateAmount checks that a is positive and has a valid currency
+func validateAmount(a *Amount) TransactionResult {
+	switch {
+	case !positiveAmount(a):
+		return TemBAD_AMOUNT
+	case badCurrency(a):
+		return TemBAD_CURRENCY
+	default:
+		return TesSUCCESS
+	}
+}
+
+// validateXRP checks that a is a positive amount of XRP
+func validateXRP(a *Amount) TransactionResult {
+	if !positiveAmount(a) || !a.IsNative() {
+		return TemBAD_AMOUNT
+	}
+	return TesSUCCESS
+}
+
+func sameIssue(a, b *Amount) bool {
+	return a.IsNative() == b.IsNative() && a.Currency == b.Currency && a.Issuer == b.Issuer
+}
+
+// firstFailure returns the first result which is not TesSUCCESS
+func firstFailure(results ...TransactionResult) TransactionResult {
+	for _, result := range results {
+		if result != TesSUCCESS {
+			return result
+		}
+	}
+	return TesSUCCESS
+}
+
+func (p *Payment) Validate() TransactionResult {
+	if result := p.validate(TxPartialPayment|TxLimitQuality|TxNoDirectRipple, p.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	partial, paths := p.flag(TxPartialPayment), p.Paths != nil && len(*p.Paths) > 0
+	max := &p.Amount
+	if p.SendMax != nil {
+		max = p.SendMax
+	}
+	xrpDirect := p.Amount.Value != nil && p.Amount.IsNative() && max.Value != nil && max.IsNative()
+	switch {
+	case p.Paths != nil && len(*p.Paths) > maxPaths:
+		return TemMALFORMED
+	case !positiveAmount(&p.Amount), !positiveAmount(max):
+		return TemBAD_AMOUNT
+	case badCurrency(&p.Amount), badCurrency(max):
+		return TemBAD_CURRENCY
+	case p.Destination.IsZero():
+		return TemDST_NEEDED
+	case p.Destination == p.Account && p.Amount.Currency == max.Currency && !paths:
+		return TemREDUNDANT
+	case xrpDirect && p.SendMax != nil:
+		return TemBAD_SEND_XRP_MAX
+	case xrpDirect && paths:
+		return TemBAD_SEND_XRP_PATHS
+	case xrpDirect && partial:
+		return TemBAD_SEND_XRP_PARTIAL
+	case xrpDirect && p.flag(TxLimitQuality):
+		return TemBAD_SEND_XRP_LIMIT
+	case xrpDirect && p.flag(TxNoDirectRipple):
+		return TemBAD_SEND_XRP_NO_DIRECT
+	}
+	if paths {
+		for _, path := range *p.Paths {
+			if len(path) == 0 || len(path) > maxPathLength {
+				return TemMALFORMED
+			}
+		}
+	}
+	if p.DeliverMin != nil {
+		switch {
+		case !partial, !positiveAmount(p.DeliverMin), !sameIssue(p.DeliverMin, &p.Amount):
+			return TemBAD_AMOUNT
+		case p.Amount.Less(*p.DeliverMin.Value):
+			return TemBAD_AMOUNT
+		}
+	}
+	return TesSUCCESS
+}
+
+func (a *AccountSet) Validate() TransactionResult {
+	if result := a.validate(TxRequireDestTag|TxOptionalDestTag|TxRequireAuth|TxOptionalAuth|TxDisallowXRP|TxAllowXRP, a.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	set := func(flag TransactionFlag, asf AccountSetFlag) bool {
+		return a.flag(flag) || (a.SetFlag != nil && AccountSetFlag(*a.SetFlag) == asf)
+	}
+	clear := func(flag TransactionFlag, asf AccountSetFlag) bool {
+		return a.flag(flag) || (a.ClearFlag != nil && AccountSetFlag(*a.ClearFlag) == asf)
+	}
+	switch {
+	case a.SetFlag != nil && a.ClearFlag != nil && *a.SetFlag != 0 && *a.SetFlag == *a.ClearFlag:
+		return TemINVALID_FLAG
+	case set(TxRequireAuth, AsfRequireAuth) && clear(TxOptionalAuth, AsfRequireAuth):
+		return TemINVALID_FLAG
+	case set(TxRequireDestTag, AsfRequireDest) && clear(TxOptionalDestTag, AsfRequireDest):
+		return TemINVALID_FLAG
+	case set(TxDisallowXRP, AsfDisallowXRP) && clear(TxAllowXRP, AsfDisallowXRP):
+		return TemINVALID_FLAG
+	case a.TransferRate != nil && *a.TransferRate != 0 && (*a.TransferRate < 1000000000 || *a.TransferRate > 2000000000):
+		return TemBAD_TRANSFER_RATE
+	case a.TickSize != nil && *a.TickSize != 0 && (*a.TickSize < 3 || *a.TickSize > 15):
+		return TemBAD_TICK_SIZE
+	case a.Domain != nil && len(*a.Domain) > maxDomainLength:
+		return TelBAD_DOMAIN
+	default:
+		return TesSUCCESS
+	}
+}
+
+func (a *AccountDelete) Validate() TransactionResult {
+	if result := a.validate(0, a.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	if a.Destination == a.Account {
+		return TemDST_IS_SRC
+	}
+	return TesSUCCESS
+}
+
+func (s *SetRegularKey) Validate() TransactionResult {
+	if result := s.validate(0, s.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	if s.RegularKey != nil && Account(*s.RegularKey) == s.Account {
+		return TemBAD_REGKEY
+	}
+	return TesSUCCESS
+}
+
+func (o *OfferCreate) Validate() TransactionResult {
+	if result := o.validate(TxPassive|TxImmediateOrCancel|TxFillOrKill|TxSell, o.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	pays, gets := &o.TakerPays, &o.TakerGets
+	switch {
+	case o.flag(TxImmediateOrCancel) && o.flag(TxFillOrKill):
+		return TemINVALID_FLAG
+	case o.Expiration != nil && *o.Expiration == 0:
+		return TemBAD_EXPIRATION
+	case o.OfferSequence != nil && *o.OfferSequence == 0:
+		return TemBAD_SEQUENCE
+	case pays.Value == nil || gets.Value == nil:
+		return TemBAD_AMOUNT
+	case pays.IsNative() && gets.IsNative():
+		return TemBAD_OFFER
+	case !positiveAmount(pays), !positiveAmount(gets):
+		return TemBAD_OFFER
+	case sameIssue(pays, gets):
+		return TemREDUNDANT
+	case badCurrency(pays), badCurrency(gets):
+		return TemBAD_CURRENCY
+	case pays.IsNative() != pays.Issuer.IsZero(), gets.IsNative() != gets.Issuer.IsZero():
+		return TemBAD_ISSUER
+	default:
+		return TesSUCCESS
+	}
+}
+
+func (o *OfferCancel) Validate() TransactionResult {
+	if result := o.validate(0, o.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	if o.OfferSequence == 0 {
+		return TemBAD_SEQUENCE
+	}
+	return TesSUCCESS
+}
+
+func (t *TrustSet) Validate() TransactionResult {
+	if result := t.validate(TxSetAuth|TxSetNoRipple|TxClearNoRipple|TxSetFreeze|TxClearFreeze|TxSetDeepFreeze|TxClearDeepFreeze, t.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	limit := &t.LimitAmount
+	switch {
+	case (t.flag(TxSetFreeze) && t.flag(TxClearFreeze)) || (t.flag(TxSetDeepFreeze) && t.flag(TxClearDeepFreeze)):
+		return TemINVALID_FLAG
+	case limit.Value == nil:
+		return TemBAD_AMOUNT
+	case limit.IsNative(), limit.IsNegative():
+		return TemBAD_LIMIT
+	case badCurrency(limit):
+		return TemBAD_CURRENCY
+	case limit.Issuer.IsZero():
+		return TemDST_NEEDED
+	case limit.Issuer == t.Account:
+		return TemDST_IS_SRC
+	default:
+		return TesSUCCESS
+	}
+}
+
+func (e *EscrowCreate) Validate() TransactionResult {
+	if result := e.validate(0, e.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	switch {
+	case validateXRP(&e.Amount) != TesSUCCESS:
+		return TemBAD_AMOUNT
+	case e.CancelAfter == nil && e.FinishAfter == nil:
+		return TemBAD_EXPIRATION
+	case e.CancelAfter != nil && e.FinishAfter != nil && *e.CancelAfter <= *e.FinishAfter:
+		return TemBAD_EXPIRATION
+	case e.FinishAfter == nil && e.Digest == nil:
+		return TemMALFORMED
+	default:
+		return TesSUCCESS
+	}
+}
+
+func (e *EscrowFinish) Validate() TransactionResult {
+	if result := e.validate(0, e.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	// The condition and its fulfillment come together
+	if (e.Digest == nil) != (e.Proof == nil) {
+		return TemMALFORMED
+	}
+	return TesSUCCESS
+}
+
+func (e *EscrowCancel) Validate() TransactionResult {
+	return e.validate(0, e.TicketSequence)
+}
+
+func (p *PaymentChannelCreate) Validate() TransactionResult {
+	if result := p.validate(0, p.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	switch {
+	case validateXRP(&p.Amount) != TesSUCCESS:
+		return TemBAD_AMOUNT
+	case p.Destination == p.Account:
+		return TemDST_IS_SRC
+	case p.PublicKey.IsZero():
+		return TemMALFORMED
+	default:
+		return TesSUCCESS
+	}
+}
+
+func (p *PaymentChannelFund) Validate() TransactionResult {
+	return firstFailure(p.validate(0, p.TicketSequence), validateXRP(&p.Amount))
+}
+
+func (p *PaymentChannelClaim) Validate() TransactionResult {
+	if result := p.validate(TxRenew|TxClose, p.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	switch {
+	case p.Balance != nil && validateXRP(p.Balance) != TesSUCCESS:
+		return TemBAD_AMOUNT
+	case p.Amount != nil && validateXRP(p.Amount) != TesSUCCESS:
+		return TemBAD_AMOUNT
+	case p.Balance != nil && p.Amount != nil && p.Amount.Less(*p.Balance.Value):
+		return TemBAD_AMOUNT
+	case p.flag(TxRenew) && p.flag(TxClose):
+		return TemMALFORMED
+	case p.Signature != nil && (p.PublicKey == nil || p.Balance == nil):
+		return TemMALFORMED
+	default:
+		return TesSUCCESS
+	}
+}
+
+func (c *CheckCreate) Validate() TransactionResult {
+	if result := c.validate(0, c.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	switch {
+	case c.Destination == c.Account:
+		return TemREDUNDANT
+	case c.Expiration != nil && *c.Expiration == 0:
+		return TemBAD_EXPIRATION
+	default:
+		return validateAmount(&c.SendMax)
+	}
+}
+
+func (c *CheckCash) Validate() TransactionResult {
+	if result := c.validate(0, c.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	switch {
+	case (c.Amount == nil) == (c.DeliverMin == nil):
+		return TemMALFORMED
+	case c.Amount != nil:
+		return validateAmount(c.Amount)
+	default:
+		return validateAmount(c.DeliverMin)
+	}
+}
+
+func (c *CheckCancel) Validate() TransactionResult {
+	return c.validate(0, c.TicketSequence)
+}
+
+func (t *TicketCreate) Validate() TransactionResult {
+	if result := t.validate(0, t.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	if t.TicketCount == nil || *t.TicketCount == 0 || *t.TicketCount > maxTicketCount {
+		return TemINVALID_COUNT
+	}
+	return TesSUCCESS
+}
+
+func (s *SignerListSet) Validate() TransactionResult {
+	if result := s.validate(0, s.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	if s.SignerQuorum == 0 {
+		// Deletes the signer list
+		if len(s.SignerEntries) > 0 {
+			return TemMALFORMED
+		}
+		return TesSUCCESS
+	}
+	if len(s.SignerEntries) == 0 || len(s.SignerEntries) > maxSignerEntries {
+		return TemMALFORMED
+	}
+	var total uint64
+	seen := make(map[Account]bool)
+	for _, entry := range s.SignerEntries {
+		item := entry.SignerEntry
+		switch {
+		case item.Account == nil || *item.Account == s.Account || seen[*item.Account]:
+			return TemBAD_SIGNER
+		case item.SignerWeight == nil || *item.SignerWeight == 0:
+			return TemBAD_WEIGHT
+		}
+		seen[*item.Account] = true
+		total += uint64(*item.SignerWeight)
+	}
+	if uint64(s.SignerQuorum) > total {
+		return TemBAD_QUORUM
+	}
+	return TesSUCCESS
+}
+
+func (s *SetDepositPreAuth) Validate() TransactionResult {
+	if result := s.validate(0, s.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	target := s.Authorize
+	if target == nil {
+		target = s.Unauthorize
+	}
+	switch {
+	case (s.Authorize == nil) == (s.Unauthorize == nil):
+		return TemMALFORMED
+	case target.IsZero():
+		return TemINVALID_ACCOUNT_ID
+	case *target == s.Account:
+		return TemCANNOT_PREAUTH_SELF
+	default:
+		return TesSUCCESS
+	}
+}
+
+func (n *NFTokenMint) Validate() TransactionResult {
+	if result := n.validate(TxBurnable|TxOnlyXRP|TxTrustLine|TxTransferable|TxMutable, n.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	switch {
+	case n.NFTokenTaxon == nil:
+		return TemMALFORMED
+	case n.TransferFee != nil && *n.TransferFee > maxTransferFee:
+		return TemBAD_NFTOKEN_TRANSFER_FEE
+	case n.TransferFee != nil && *n.TransferFee > 0 && !n.flag(TxTransferable):
+		return TemMALFORMED
+	case n.Issuer != nil && *n.Issuer == n.Account:
+		return TemMALFORMED
+	case n.URI != nil && (len(*n.URI) == 0 || len(*n.URI) > maxURILength):
+		return TemMALFORMED
+	default:
+		return TesSUCCESS
+	}
+}
+
+func (n *NFTokenBurn) Validate() TransactionResult {
+	return n.validate(0, n.TicketSequence)
+}
+
+func (n *NFTokenCreateOffer) Validate() TransactionResult {
+	if result := n.validate(TxSellNFToken, n.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	sell := n.flag(TxSellNFToken)
+	switch {
+	case n.NFTokenID == nil || n.Amount == nil || n.Amount.Value == nil:
+		return TemMALFORMED
+	case n.Amount.IsNegative(), !sell && n.Amount.IsZero(), badCurrency(n.Amount):
+		return TemBAD_AMOUNT
+	case !n.Amount.IsNative() && (n.Amount.IsZero() || ParseNFTokenID(*n.NFTokenID).Flags&TxOnlyXRP != 0):
+		return TemBAD_AMOUNT
+	case n.Expiration != nil && *n.Expiration == 0:
+		return TemBAD_EXPIRATION
+	case sell && n.Owner != nil:
+		return TemMALFORMED
+	case !sell && (n.Owner == nil || *n.Owner == n.Account):
+		return TemMALFORMED
+	case n.Destination != nil && *n.Destination == n.Account:
+		return TemMALFORMED
+	default:
+		return TesSUCCESS
+	}
+}
+
+func (n *NFTCancelOffer) Validate() TransactionResult {
+	if result := n.validate(0, n.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	if n.NFTokenOffers == nil || len(*n.NFTokenOffers) == 0 || len(*n.NFTokenOffers) > maxOffersToCancel {
+		return TemMALFORMED
+	}
+	seen := make(map[Hash256]bool)
+	for _, offer := range *n.NFTokenOffers {
+		if seen[offer] {
+			return TemMALFORMED
+		}
+		seen[offer] = true
+	}
+	return TesSUCCESS
+}
+
+func (n *NFTAcceptOffer) Validate() TransactionResult {
+	if result := n.validate(0, n.TicketSequence); result != TesSUCCESS {
+		return result
+	}
+	switch {
+	case n.NFTokenBuyOffer == nil && n.NFTokenSellOffer == nil:
+		return TemMALFORMED
+	case n.NFTokenBrokerFee != nil && (n.NFTokenBuyOffer == nil || n.NFTokenSellOffer == nil):
+		return TemMALFORMED
+	case n.NFTokenBrokerFee != nil && !positiveAmount(n.NFTokenBrokerFee):
+		return TemMALFORMED
+	default:
+		return TesSUCCESS
+	}
+}
+
+func (c *Clawback) Validate() TransactionResult {
+	if result := c.validate(0, nil); result != TesSUCCESS {
+		return result
+	}
+	switch {
+	// The issuer field holds the holder to claw back from
+	case c.Amount.Value == nil || c.Amount.IsNative() || !positiveAmount(&c.Amount), c.Amount.Issuer == c.Account:
+		return TemBAD_AMOUNT
+	default:
+		return TesSUCCESS
+	}
+}
+
+func validateAssets(asset, asset2 Issue) TransactionResult {
+	if asset == asset2 {
+		return TemBAD_AMM_TOKENS
+	}
+	return TesSUCCESS
+}
+
+func validateTradingFee(fee uint16) TransactionResult {
+	if fee > maxTradingFee {
+		return TemBAD_FEE
+	}
+	return TesSUCCESS
+}
+
+func (a *AMMCreate) Validate() TransactionResult {
+	if result := a.validate(0, nil); result != TesSUCCESS {
+		return result
+	}
+	switch {
+	case a.Amount.Value == nil || a.Amount2.Value == nil:
+		return TemBAD_AMOUNT
+	case sameIssue(&a.Amount, &a.Amount2):
+		return TemBAD_AMM_TOKENS
+	default:
+		return firstFailure(validateAmount(&a.Amount), validateAmount(&a.Amount2), validateTradingFee(a.TradingFee))
+	}
+}
+
+// ammMode returns the single mode flag of an AMMDeposit or AMMWithdraw
+func ammMode(flags *TransactionFlag, modes TransactionFlag) (TransactionFlag, bool) {
+	if flags == nil {
+		return 0, false
+	}
+	mode := *flags & modes
+	return mode, mode != 0 && mode&(mode-1) == 0
+}
+
+type ammField int
+
+const (
+	ammAbsent ammField = iota
+	ammRequired
+	ammOptional
+)
+
+// ammFields checks which optional amounts are present for a mode
+func ammFields(amount, amount2, ePrice, lpTokens *Amount, want ...ammField) bool {
+	for i, a := range []*Amount{amount, amount2, ePrice, lpTokens} {
+		switch {
+		case want[i] == ammRequired && a == nil, want[i] == ammAbsent && a != nil:
+			return false
+		}
+	}
+	return true
+}
+
+func validateAMMAmounts(amounts ...*Amount) TransactionResult {
+	for _, amount := range amounts {
+		if amount != nil {
+			if result := validateAmount(amount); result != TesSUCCESS {
+				return result
+			}
+		}
+	}
+	return TesSUCCESS
+}
+
+func (a *AMMDeposit) Validate() TransactionResult {
+	modes := TxLPToken | TxSingleAsset | TxTwoAsset | TxOneAssetLPToken | TxLimitLPToken | TxTwoAssetIfEmpty
+	if result := a.validate(modes, nil); result != TesSUCCESS {
+		return result
+	}
+	if result := validateAssets(a.Asset, a.Asset2); result != TesSUCCESS {
+		return result
+	}
+	mode, ok := ammMode(a.Flags, modes)
+	if !ok {
+		return TemMALFORMED
+	}
+	fee := a.TradingFee != nil
+	switch mode {
+	case TxLPToken:
+		// Amount and Amount2 are an optional pair of minimums
+		ok = ammFields(a.Amount, a.Amount2, a.EPrice, a.LPTokenOut, ammOptional, ammOptional, ammAbsent, ammRequired) && (a.Amount == nil) == (a.Amount2 == nil) && !fee
+	case TxSingleAsset:
+		ok = ammFields(a.Amount, a.Amount2, a.EPrice, a.LPTokenOut, ammRequired, ammAbsent, ammAbsent, ammOptional) && !fee
+	case TxTwoAsset:
+		ok = ammFields(a.Amount, a.Amount2, a.EPrice, a.LPTokenOut, ammRequired, ammRequired, ammAbsent, ammOptional) && !fee
+	case TxOneAssetLPToken:
+		ok = ammFields(a.Amount, a.Amount2, a.EPrice, a.LPTokenOut, ammRequired, ammAbsent, ammAbsent, ammRequired) && !fee
+	case TxLimitLPToken:
+		ok = ammFields(a.Amount, a.Amount2, a.EPrice, a.LPTokenOut, ammRequired, ammAbsent, ammRequired, ammAbsent) && !fee
+	case TxTwoAssetIfEmpty:
+		ok = ammFields(a.Amount, a.Amount2, a.EPrice, a.LPTokenOut, ammRequired, ammRequired, ammAbsent, ammAbsent)
+	}
+	if !ok {
+		return TemMALFORMED
+	}
+	if fee {
+		if result := validateTradingFee(*a.TradingFee); result != TesSUCCESS {
+			return result
+		}
+	}
+	if a.LPTokenOut != nil && !positiveAmount(a.LPTokenOut) {
+		return TemBAD_AMM_TOKENS
+	}
+	return validateAMMAmounts(a.Amount, a.Amount2, a.EPrice)
+}
+
+func (a *AMMWithdraw) Validate() TransactionResult {
+	modes := TxLPToken | TxWithdrawAll | TxOneAssetWithdrawAll | TxSingleAsset | TxTwoAsset | TxOneAssetLPToken | TxLimitLPToken
+	if result := a.validate(modes, nil); result != TesSUCCESS {
+		return result
+	}
+	if result := validateAssets(a.Asset, a.Asset2); result != TesSUCCESS {
+		return result
+	}
+	mode, ok := ammMode(a.Flags, modes)
+	if !ok {
+		return TemMALFORMED
+	}
+	switch mode {
+	case TxLPToken:
+		// Amount and Amount2 are an optional pair of minimums
+		ok = ammFields(a.Amount, a.Amount2, a.EPrice, a.LPTokenIn, ammOptional, ammOptional, ammAbsent, ammRequired) && (a.Amount == nil) == (a.Amount2 == nil)
+	case TxWithdrawAll:
+		ok = ammFields(a.Amount, a.Amount2, a.EPrice, a.LPTokenIn, ammAbsent, ammAbsent, ammAbsent, ammAbsent)
+	case TxOneAssetWithdrawAll, TxSingleAsset:
+		ok = ammFields(a.Amount, a.Amount2, a.EPrice, a.LPTokenIn, ammRequired, ammAbsent, ammAbsent, ammAbsent)
+	case TxTwoAsset:
+		ok = ammFields(a.Amount, a.Amount2, a.EPrice, a.LPTokenIn, ammRequired, ammRequired, ammAbsent, ammAbsent)
+	case TxOneAssetLPToken:
+		ok = ammFields(a.Amount, a.Amount2, a.EPrice, a.LPTokenIn, ammRequired, ammAbsent, ammAbsent, ammRequired)
+	case TxLimitLPToken:
+		ok = ammFields(a.Amount, a.Amount2, a.EPrice, a.LPTokenIn, ammRequired, ammAbsent, ammRequired, ammAbsent)
+	}
+	if !ok {
+		return TemMALFORMED
+	}
+	if a.LPTokenIn != nil && !positiveAmount(a.LPTokenIn) {
+		return TemBAD_AMM_TOKENS
+	}
+	return validateAMMAmounts(a.Amount, a.Amount2, a.EPrice)
+}
+
+func (a *AMMVote) Validate() TransactionResult {
+	return firstFailure(a.validate(0, nil), validateAssets(a.Asset, a.Asset2), validateTradingFee(a.TradingFee))
+}
+
+func (a *AMMBid) Validate() TransactionResult {
+	if result := firstFailure(a.validate(0, nil), validateAssets(a.Asset, a.Asset2)); result != TesSUCCESS {
+		return result
+	}
+	switch {
+	case len(a.AuthAccounts) > maxAuthAccounts:
+		return TemMALFORMED
+	case a.BidMin != nil && !positiveAmount(a.BidMin), a.BidMax != nil && !positiveAmount(a.BidMax):
+		return TemBAD_AMOUNT
+	}
+	for _, auth := range a.AuthAccounts {
+		if auth.AuthAccount.Account == a.Account {
+			return TemMALFORMED
+		}
+	}
+	return TesSUCCESS
+}
+
+func (a *AMMDelete) Validate() TransactionResult {
+	return firstFailure(a.validate(0, nil), validateAssets(a.Asset, a.Asset2))
+}
+
+func (s *SetFee) Validate() TransactionResult {
+	return s.validatePseudo()
+}
+
+func (a *Amendment) Validate() TransactionResult {
+	if result := a.validatePseudo(); result != TesSUCCESS {
+		return result
+	}
+	if a.Flags != nil && *a.Flags&^(TxGotMajority|TxLostMajority) != 0 {
+		return TemINVALID_FLAG
+	}
+	return TesSUCCESS
+}
+
+func (u *UNLModify) Validate() TransactionResult {
+	if result := u.validatePseudo(); result != TesSUCCESS {
+		return result
+	}
+	if u.UNLModifyDisabling > 1 || u.UNLModifyValidator == nil || len(*u.UNLModifyValidator) == 0 {
+		return TemMALFORMED
+	}
+	return TesSUCCESS
+}
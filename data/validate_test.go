@@ -0,0 +1,291 @@
+package data
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+type ValidateSuite struct{}
+
+var _ = Suite(&ValidateSuite{})
+
+func validateOffer(pays, gets string, flags TransactionFlag) *OfferCreate {
+	offer := &OfferCreate{
+		TxBase:    txBaseCheck(OFFER_CREATE),
+		TakerPays: *usdCheck(pays),
+		TakerGets: *amountCheck(gets + "/XRP"),
+	}
+	offer.Flags = &flags
+	return offer
+}
+
+func validatePayment(amount string) *Payment {
+	return &Payment{
+		TxBase:      txBaseCheck(PAYMENT),
+		Destination: accountCheck(testDestination),
+		Amount:      *amountCheck(amount),
+	}
+}
+
+func (s *ValidateSuite) TestValidated(c *C) {
+	files, err := filepath.Glob("testdata/transaction_*.json")
+	c.Assert(err, IsNil)
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		c.Assert(err, IsNil)
+		var txm TransactionWithMetaData
+		c.Assert(json.Unmarshal(b, &txm), IsNil)
+		c.Check(txm.Transaction.Validate(), Equals, TesSUCCESS, Commentf(f))
+	}
+}
+
+func (s *ValidateSuite) TestBase(c *C) {
+	p := validatePayment("1/XRP")
+	c.Check(p.Validate(), Equals, TesSUCCESS)
+	p.Fee = *amountCheck("-1/XRP").Value
+	c.Check(p.Validate(), Equals, TemBAD_FEE)
+
+	p = validatePayment("1/XRP")
+	ticket := uint32(5)
+	p.TicketSequence = &ticket
+	c.Check(p.Validate(), Equals, TemSEQ_AND_TICKET)
+
+	p = validatePayment("1/XRP")
+	flags := TxSell
+	p.Flags = &flags
+	c.Check(p.Validate(), Equals, TemINVALID_FLAG)
+
+	p = validatePayment("1/XRP")
+	p.Account = Account{}
+	c.Check(p.Validate(), Equals, TemBAD_SRC_ACCOUNT)
+}
+
+func (s *ValidateSuite) TestPayment(c *C) {
+	c.Check(validatePayment("0/XRP").Validate(), Equals, TemBAD_AMOUNT)
+	p := validatePayment("1/USD/" + testIssuer)
+	p.Amount.Currency = xrpCurrencyCode
+	c.Check(p.Validate(), Equals, TemBAD_CURRENCY)
+
+	p = validatePayment("1/XRP")
+	p.SendMax = amountCheck("2/XRP")
+	c.Check(p.Validate(), Equals, TemBAD_SEND_XRP_MAX)
+
+	p = validatePayment("1/XRP")
+	flags := TxPartialPayment
+	p.Flags = &flags
+	c.Check(p.Validate(), Equals, TemBAD_SEND_XRP_PARTIAL)
+
+	p = validatePayment("1/XRP")
+	p.Destination = p.Account
+	c.Check(p.Validate(), Equals, TemREDUNDANT)
+
+	p = validatePayment("10/USD/" + testIssuer)
+	p.DeliverMin = usdCheck("5")
+	c.Check(p.Validate(), Equals, TemBAD_AMOUNT)
+	p.Flags = &flags
+	c.Check(p.Validate(), Equals, TesSUCCESS)
+	p.DeliverMin = usdCheck("20")
+	c.Check(p.Validate(), Equals, TemBAD_AMOUNT)
+}
+
+func (s *ValidateSuite) TestOfferCreate(c *C) {
+	o := validateOffer("10", "5", TxImmediateOrCancel)
+	c.Check(o.Validate(), Equals, TesSUCCESS)
+	*o.Flags |= TxFillOrKill
+	c.Check(o.Validate(), Equals, TemINVALID_FLAG)
+
+	o = validateOffer("10", "5", 0)
+	o.TakerGets = o.TakerPays
+	c.Check(o.Validate(), Equals, TemREDUNDANT)
+
+	o = validateOffer("10", "5", 0)
+	o.TakerPays = *amountCheck("1/XRP")
+	c.Check(o.Validate(), Equals, TemBAD_OFFER)
+}
+
+func (s *ValidateSuite) TestCheckCash(c *C) {
+	check := &CheckCash{TxBase: txBaseCheck(CHECK_CASH)}
+	c.Check(check.Validate(), Equals, TemMALFORMED)
+	check.Amount = usdCheck("1")
+	c.Check(check.Validate(), Equals, TesSUCCESS)
+	check.DeliverMin = usdCheck("1")
+	c.Check(check.Validate(), Equals, TemMALFORMED)
+	check.Amount = nil
+	check.DeliverMin = usdCheck("-1")
+	c.Check(check.Validate(), Equals, TemBAD_AMOUNT)
+}
+
+func (s *ValidateSuite) TestAccountSet(c *C) {
+	a := &AccountSet{TxBase: txBaseCheck(ACCOUNT_SET)}
+	rate := uint32(500000000)
+	a.TransferRate = &rate
+	c.Check(a.Validate(), Equals, TemBAD_TRANSFER_RATE)
+	rate = 1005000000
+	c.Check(a.Validate(), Equals, TesSUCCESS)
+
+	set, clear := uint32(AsfRequireAuth), uint32(AsfRequireAuth)
+	a.SetFlag, a.ClearFlag = &set, &clear
+	c.Check(a.Validate(), Equals, TemINVALID_FLAG)
+	flags := TxOptionalAuth
+	a.ClearFlag, a.Flags = nil, &flags
+	c.Check(a.Validate(), Equals, TemINVALID_FLAG)
+
+	// Zero means no flag
+	set, clear = 0, 0
+	a.SetFlag, a.ClearFlag, a.Flags = &set, &clear, nil
+	c.Check(a.Validate(), Equals, TesSUCCESS)
+}
+
+func (s *ValidateSuite) TestEscrowFinish(c *C) {
+	e := &EscrowFinish{TxBase: txBaseCheck(ESCROW_FINISH), Owner: accountCheck(testDestination)}
+	c.Check(e.Validate(), Equals, TesSUCCESS)
+	e.Digest = &Hash256{1}
+	c.Check(e.Validate(), Equals, TemMALFORMED)
+	e.Proof = &Hash256{2}
+	c.Check(e.Validate(), Equals, TesSUCCESS)
+	e.Digest = nil
+	c.Check(e.Validate(), Equals, TemMALFORMED)
+}
+
+func (s *ValidateSuite) TestNFTokenCreateOffer(c *C) {
+	flags := TxSellNFToken
+	id := Hash256{}
+	n := &NFTokenCreateOffer{TxBase: txBaseCheck(NFTOKEN_CREATE_OFFER), NFTokenID: &id, Amount: amountCheck("0/XRP")}
+	n.Flags = &flags
+	c.Check(n.Validate(), Equals, TesSUCCESS)
+	n.Amount = usdCheck("0")
+	c.Check(n.Validate(), Equals, TemBAD_AMOUNT)
+	n.Amount = usdCheck("1")
+	c.Check(n.Validate(), Equals, TesSUCCESS)
+
+	// The token may only be traded for XRP
+	id[1] = byte(TxOnlyXRP)
+	c.Check(n.Validate(), Equals, TemBAD_AMOUNT)
+	n.Amount = amountCheck("1/XRP")
+	c.Check(n.Validate(), Equals, TesSUCCESS)
+}
+
+func (s *ValidateSuite) TestClawback(c *C) {
+	claw := &Clawback{TxBase: txBaseCheck(CLAWBACK), Amount: *amountCheck("1/USD/" + testDestination)}
+	c.Check(claw.Validate(), Equals, TesSUCCESS)
+	claw.Amount = *amountCheck("1/USD/" + testAccount)
+	c.Check(claw.Validate(), Equals, TemBAD_AMOUNT)
+	claw.Amount = *amountCheck("1/XRP")
+	c.Check(claw.Validate(), Equals, TemBAD_AMOUNT)
+}
+
+func (s *ValidateSuite) TestTicketCreate(c *C) {
+	t := &TicketCreate{TxBase: txBaseCheck(TICKET_CREATE)}
+	c.Check(t.Validate(), Equals, TemINVALID_COUNT)
+	count := uint32(maxTicketCount + 1)
+	t.TicketCount = &count
+	c.Check(t.Validate(), Equals, TemINVALID_COUNT)
+	count = 10
+	c.Check(t.Validate(), Equals, TesSUCCESS)
+}
+
+func (s *ValidateSuite) TestAMMDeposit(c *C) {
+	fee := uint16(100)
+	for i, test := range []struct {
+		flags                           TransactionFlag
+		amount, amount2, ePrice, tokens bool
+		fee                             bool
+		expected                        TransactionResult
+	}{
+		{TxLPToken, false, false, false, true, false, TesSUCCESS},
+		{TxLPToken, true, true, false, true, false, TesSUCCESS},
+		{TxLPToken, true, false, false, true, false, TemMALFORMED},
+		{TxLPToken, false, true, false, true, false, TemMALFORMED},
+		{TxLPToken, false, false, false, false, false, TemMALFORMED},
+		{TxLPToken, false, false, false, true, true, TemMALFORMED},
+		{TxSingleAsset, true, false, false, false, false, TesSUCCESS},
+		{TxSingleAsset, true, false, false, true, false, TesSUCCESS},
+		{TxSingleAsset, true, false, false, false, true, TemMALFORMED},
+		{TxSingleAsset, true, true, false, false, false, TemMALFORMED},
+		{TxTwoAsset, true, true, false, false, false, TesSUCCESS},
+		{TxTwoAsset, true, true, false, true, false, TesSUCCESS},
+		{TxTwoAsset, true, false, false, false, false, TemMALFORMED},
+		{TxTwoAsset, true, true, false, false, true, TemMALFORMED},
+		{TxOneAssetLPToken, true, false, false, true, false, TesSUCCESS},
+		{TxOneAssetLPToken, true, false, false, false, false, TemMALFORMED},
+		{TxLimitLPToken, true, false, true, false, false, TesSUCCESS},
+		{TxLimitLPToken, true, false, true, true, false, TemMALFORMED},
+		{TxTwoAssetIfEmpty, true, true, false, false, true, TesSUCCESS},
+		{TxTwoAssetIfEmpty, true, true, false, true, false, TemMALFORMED},
+		{TxLPToken | TxSingleAsset, true, false, false, true, false, TemMALFORMED},
+	} {
+		flags := test.flags
+		d := &AMMDeposit{
+			TxBase: txBaseCheck(AMM_DEPOSIT),
+			Asset2: usdCheck("1").Issue(),
+		}
+		d.Flags = &flags
+		if test.amount {
+			d.Amount = amountCheck("1/XRP")
+		}
+		if test.amount2 {
+			d.Amount2 = usdCheck("1")
+		}
+		if test.ePrice {
+			d.EPrice = amountCheck("1/XRP")
+		}
+		if test.tokens {
+			d.LPTokenOut = usdCheck("1")
+		}
+		if test.fee {
+			d.TradingFee = &fee
+		}
+		c.Check(d.Validate(), Equals, test.expected, Commentf("%d", i))
+	}
+}
+
+func (s *ValidateSuite) TestAMMWithdraw(c *C) {
+	for i, test := range []struct {
+		flags                           TransactionFlag
+		amount, amount2, ePrice, tokens bool
+		expected                        TransactionResult
+	}{
+		{TxLPToken, false, false, false, true, TesSUCCESS},
+		{TxLPToken, true, true, false, true, TesSUCCESS},
+		{TxLPToken, true, false, false, true, TemMALFORMED},
+		{TxWithdrawAll, false, false, false, false, TesSUCCESS},
+		{TxWithdrawAll, true, false, false, false, TemMALFORMED},
+		{TxOneAssetWithdrawAll, true, false, false, false, TesSUCCESS},
+		{TxOneAssetWithdrawAll, false, false, false, false, TemMALFORMED},
+		{TxSingleAsset, true, false, false, false, TesSUCCESS},
+		{TxSingleAsset, true, false, false, true, TemMALFORMED},
+		{TxTwoAsset, true, true, false, false, TesSUCCESS},
+		{TxTwoAsset, true, true, false, true, TemMALFORMED},
+		{TxOneAssetLPToken, true, false, false, true, TesSUCCESS},
+		{TxLimitLPToken, true, false, true, false, TesSUCCESS},
+		{TxLimitLPToken, true, false, false, false, TemMALFORMED},
+	} {
+		flags := test.flags
+		w := &AMMWithdraw{
+			TxBase: txBaseCheck(AMM_WITHDRAW),
+			Asset2: usdCheck("1").Issue(),
+		}
+		w.Flags = &flags
+		if test.amount {
+			w.Amount = amountCheck("1/XRP")
+		}
+		if test.amount2 {
+			w.Amount2 = usdCheck("1")
+		}
+		if test.ePrice {
+			w.EPrice = amountCheck("1/XRP")
+		}
+		if test.tokens {
+			w.LPTokenIn = usdCheck("1")
+		}
+		c.Check(w.Validate(), Equals, test.expected, Commentf("%d", i))
+	}
+	w := &AMMWithdraw{TxBase: txBaseCheck(AMM_WITHDRAW), LPTokenIn: usdCheck("-1")}
+	flags := TxLPToken
+	w.Flags = &flags
+	w.Asset2 = usdCheck("1").Issue()
+	c.Check(w.Validate(), Equals, TemBAD_AMM_TOKENS)
+}
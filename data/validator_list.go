@@ -0,0 +1,230 @@
+package data
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rubblelabs/ripple/crypto"
+)
+
+// A ValidatorList is a list of trusted validators (UNL) as served by a
+// publisher such as vl.ripple.com. Each blob is the base64 of a JSON
+// ValidatorListBlob signed by the ephemeral key in the publisher's
+// manifest. Version 1 has a single blob, version 2 a series of blobs which
+// become effective one after another.
+type ValidatorList struct {
+	PublicKey PublicKey `json:"public_key"`
+	SignedBlob
+	BlobsV2 []SignedBlob `json:"blobs_v2,omitempty"`
+	Version uint32       `json:"version"`
+}
+
+// SignedBlob is a blob and its signature. The manifest is that of the
+// publisher, which a version 2 blob may override.
+type SignedBlob struct {
+	Blob      string         `json:"blob,omitempty"`
+	Signature VariableLength `json:"signature,omitempty"`
+	Manifest  string         `json:"manifest,omitempty"`
+}
+
+type ValidatorListBlob struct {
+	Sequence   uint32            `json:"sequence"`
+	Effective  *RippleTime       `json:"effective,omitempty"`
+	Expiration RippleTime        `json:"expiration"`
+	Validators []ListedValidator `json:"validators"`
+}
+
+// ListedValidator is a validator's master key and, unless the validator
+// signs with its master key, its manifest
+type ListedValidator struct {
+	ValidationPublicKey PublicKey `json:"validation_public_key"`
+	Manifest            string    `json:"manifest,omitempty"`
+}
+
+// ParseValidatorList parses the JSON served by a publisher. Use Verify to
+// check the signatures.
+func ParseValidatorList(b []byte) (*ValidatorList, error) {
+	var l ValidatorList
+	if err := json.Unmarshal(b, &l); err != nil {
+		return nil, err
+	}
+	switch {
+	case l.Version == 1 && len(l.Blob) > 0 && len(l.BlobsV2) == 0:
+	case l.Version == 2 && len(l.Blob) == 0 && len(l.BlobsV2) > 0:
+	default:
+		return nil, fmt.Errorf("Bad validator list version: %d", l.Version)
+	}
+	return &l, nil
+}
+
+// NewValidatorListBlob returns a blob listing the validators with the
+// given manifests. effective may be nil for the blob of a version 1 list.
+func NewValidatorListBlob(sequence uint32, effective *RippleTime, expiration RippleTime, manifests ...*Manifest) (*ValidatorListBlob, error) {
+	blob := &ValidatorListBlob{
+		Sequence:   sequence,
+		Effective:  effective,
+		Expiration: expiration,
+	}
+	for _, m := range manifests {
+		manifest, err := m.Base64()
+		if err != nil {
+			return nil, err
+		}
+		blob.Validators = append(blob.Validators, ListedValidator{
+			ValidationPublicKey: m.PublicKey,
+			Manifest:            manifest,
+		})
+	}
+	return blob, nil
+}
+
+// NewValidatorList returns a list of the blobs signed by signer for the
+// publisher's manifest. A single blob gives a version 1 list.
+func NewValidatorList(publisher *Manifest, signer crypto.Signer, blobs ...*ValidatorListBlob) (*ValidatorList, error) {
+	if publisher.Revoked() {
+		return nil, fmt.Errorf("Publisher manifest is revoked")
+	}
+	manifest, err := publisher.Base64()
+	if err != nil {
+		return nil, err
+	}
+	l := &ValidatorList{
+		PublicKey: publisher.PublicKey,
+		Version:   1,
+	}
+	for _, blob := range blobs {
+		b, err := json.Marshal(blob)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := signBlob(signer, publisher.SigningPubKey.Bytes(), b)
+		if err != nil {
+			return nil, err
+		}
+		l.BlobsV2 = append(l.BlobsV2, SignedBlob{
+			Blob:      base64.StdEncoding.EncodeToString(b),
+			Signature: sig,
+		})
+	}
+	switch len(l.BlobsV2) {
+	case 0:
+		return nil, fmt.Errorf("No validator list blobs")
+	case 1:
+		l.SignedBlob, l.BlobsV2 = l.BlobsV2[0], nil
+	default:
+		l.Version = 2
+	}
+	l.Manifest = manifest
+	return l, nil
+}
+
+func signBlob(signer crypto.Signer, publicKey, blob []byte) (VariableLength, error) {
+	return sign(signer, publicKey, crypto.Sha512Half(blob), blob)
+}
+
+// Blobs returns the signed blobs of either version
+func (l *ValidatorList) Blobs() []SignedBlob {
+	if l.Version == 1 {
+		return []SignedBlob{l.SignedBlob}
+	}
+	return l.BlobsV2
+}
+
+// Verify checks the manifests and signatures of the list and returns its
+// blobs in the order given
+func (l *ValidatorList) Verify() ([]*ValidatorListBlob, error) {
+	publisher, err := l.publisherManifest(l.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	var blobs []*ValidatorListBlob
+	for i, signed := range l.Blobs() {
+		m := publisher
+		if l.Version == 2 && len(signed.Manifest) > 0 {
+			if m, err = l.publisherManifest(signed.Manifest); err != nil {
+				return nil, err
+			}
+		}
+		blob, err := signed.verify(m)
+		if err != nil {
+			return nil, fmt.Errorf("Validator list blob %d: %s", i, err)
+		}
+		blobs = append(blobs, blob)
+	}
+	return blobs, nil
+}
+
+func (l *ValidatorList) publisherManifest(s string) (*Manifest, error) {
+	m, err := ParseManifest(s)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case m.PublicKey != l.PublicKey:
+		return nil, fmt.Errorf("Publisher manifest is for %s", m.MasterKey())
+	case m.Revoked():
+		return nil, fmt.Errorf("Publisher manifest is revoked")
+	}
+	return m, m.Verify()
+}
+
+func (s *SignedBlob) verify(publisher *Manifest) (*ValidatorListBlob, error) {
+	b, err := base64.StdEncoding.DecodeString(s.Blob)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := crypto.Verify(publisher.SigningPubKey.Bytes(), crypto.Sha512Half(b), b, s.Signature)
+	switch {
+	case err != nil:
+		return nil, err
+	case !ok:
+		return nil, fmt.Errorf("Bad signature")
+	}
+	var blob ValidatorListBlob
+	if err := json.Unmarshal(b, &blob); err != nil {
+		return nil, err
+	}
+	for _, v := range blob.Validators {
+		if len(v.Manifest) == 0 {
+			continue
+		}
+		m, err := ParseManifest(v.Manifest)
+		if err != nil {
+			return nil, err
+		}
+		if m.PublicKey != v.ValidationPublicKey {
+			return nil, fmt.Errorf("Manifest is for %s not %s", m.MasterKey(), v.ValidationPublicKey.NodePublicKey())
+		}
+		if err := m.Verify(); err != nil {
+			return nil, fmt.Errorf("%s: %s", m.MasterKey(), err)
+		}
+	}
+	return &blob, nil
+}
+
+// Active returns the verified blob in effect at now
+func (l *ValidatorList) Active(now RippleTime) (*ValidatorListBlob, error) {
+	blobs, err := l.Verify()
+	if err != nil {
+		return nil, err
+	}
+	var active *ValidatorListBlob
+	for _, blob := range blobs {
+		if blob.Current(now) && (active == nil || blob.Sequence > active.Sequence) {
+			active = blob
+		}
+	}
+	if active == nil {
+		return nil, fmt.Errorf("No validator list in effect at %s", now.String())
+	}
+	return active, nil
+}
+
+// Current returns true if the blob is in effect at now
+func (b *ValidatorListBlob) Current(now RippleTime) bool {
+	if b.Effective != nil && now.Uint32() < b.Effective.Uint32() {
+		return false
+	}
+	return now.Uint32() < b.Expiration.Uint32()
+}
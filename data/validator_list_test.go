@@ -0,0 +1,200 @@
+package data
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/rubblelabs/ripple/crypto"
+	. "gopkg.in/check.v1"
+)
+
+type ValidatorListSuite struct{}
+
+var _ = Suite(&ValidatorListSuite{})
+
+func signedManifest(c *C, name string) (*Manifest, *crypto.KeySigner) {
+	masterKey, err := crypto.NewEd25519Key([]byte(name + " master"))
+	c.Assert(err, IsNil)
+	signingKey, err := crypto.NewECDSAKey([]byte(name + " signing"))
+	c.Assert(err, IsNil)
+	master, signing := crypto.NewKeySigner(masterKey, nil), crypto.NewKeySigner(signingKey, nil)
+	m, err := NewManifest(1, master.Public(), signing.Public(), "")
+	c.Assert(err, IsNil)
+	c.Assert(m.Sign(master, signing), IsNil)
+	return m, signing
+}
+
+func validatorListBlob(c *C, sequence uint32, effective *RippleTime, expiration uint32) *ValidatorListBlob {
+	first, _ := signedManifest(c, "first")
+	second, _ := signedManifest(c, "second")
+	blob, err := NewValidatorListBlob(sequence, effective, *NewRippleTime(expiration), first, second)
+	c.Assert(err, IsNil)
+	return blob
+}
+
+// roundTrip checks that the list survives being served as JSON
+func roundTrip(c *C, l *ValidatorList) *ValidatorList {
+	b, err := json.Marshal(l)
+	c.Assert(err, IsNil)
+	parsed, err := ParseValidatorList(b)
+	c.Assert(err, IsNil)
+	return parsed
+}
+
+func (s *ValidatorListSuite) TestVersion1(c *C) {
+	publisher, signer := signedManifest(c, "publisher")
+	l, err := NewValidatorList(publisher, signer, validatorListBlob(c, 1, nil, 1000))
+	c.Assert(err, IsNil)
+	c.Check(l.Version, Equals, uint32(1))
+	l = roundTrip(c, l)
+	blobs, err := l.Verify()
+	c.Assert(err, IsNil)
+	c.Assert(blobs, HasLen, 1)
+	c.Check(blobs[0].Validators, HasLen, 2)
+
+	active, err := l.Active(*NewRippleTime(999))
+	c.Assert(err, IsNil)
+	c.Check(active.Sequence, Equals, uint32(1))
+	_, err = l.Active(*NewRippleTime(1000))
+	c.Check(err, ErrorMatches, "No validator list in effect at 2000-Jan-01 00:16:40 UTC")
+}
+
+func (s *ValidatorListSuite) TestVersion2(c *C) {
+	publisher, signer := signedManifest(c, "publisher")
+	l, err := NewValidatorList(publisher, signer,
+		validatorListBlob(c, 1, nil, 2000),
+		validatorListBlob(c, 2, NewRippleTime(1000), 3000),
+	)
+	c.Assert(err, IsNil)
+	c.Check(l.Version, Equals, uint32(2))
+	l = roundTrip(c, l)
+	for _, test := range []struct {
+		now      uint32
+		sequence uint32
+	}{
+		{999, 1},
+		{1000, 2},
+		{2999, 2},
+	} {
+		active, err := l.Active(*NewRippleTime(test.now))
+		c.Assert(err, IsNil)
+		c.Check(active.Sequence, Equals, test.sequence)
+	}
+}
+
+func (s *ValidatorListSuite) TestBadList(c *C) {
+	publisher, signer := signedManifest(c, "publisher")
+	other, otherSigner := signedManifest(c, "other")
+	l, err := NewValidatorList(publisher, signer, validatorListBlob(c, 1, nil, 1000))
+	c.Assert(err, IsNil)
+
+	l.Signature[10]++
+	_, err = l.Verify()
+	c.Check(err, ErrorMatches, "Validator list blob 0: Bad signature")
+
+	l, err = NewValidatorList(other, otherSigner, validatorListBlob(c, 1, nil, 1000))
+	c.Assert(err, IsNil)
+	l.PublicKey = publisher.PublicKey
+	_, err = l.Verify()
+	c.Check(err, ErrorMatches, "Publisher manifest is for .*")
+
+	// A validator listed under the wrong key
+	blob := validatorListBlob(c, 1, nil, 1000)
+	blob.Validators[0].ValidationPublicKey = other.PublicKey
+	l, err = NewValidatorList(publisher, signer, blob)
+	c.Assert(err, IsNil)
+	_, err = l.Verify()
+	c.Check(err, ErrorMatches, "Validator list blob 0: Manifest is for .*")
+
+	_, err = ParseValidatorList([]byte(`{"version":3}`))
+	c.Check(err, ErrorMatches, "Bad validator list version: 3")
+}
+
+// Lists may name a validator by its master key alone. The other validator
+// has a published manifest.
+func (s *ValidatorListSuite) TestWithoutManifest(c *C) {
+	publisher, signer := signedManifest(c, "publisher")
+	published, err := ParseManifest(publishedManifest)
+	c.Assert(err, IsNil)
+	blob, err := NewValidatorListBlob(1, nil, *NewRippleTime(1000), published)
+	c.Assert(err, IsNil)
+	master, _ := signedManifest(c, "master")
+	blob.Validators = append(blob.Validators, ListedValidator{ValidationPublicKey: master.PublicKey})
+	l, err := NewValidatorList(publisher, signer, blob)
+	c.Assert(err, IsNil)
+	b, err := json.Marshal(l)
+	c.Assert(err, IsNil)
+	l, err = ParseValidatorList(b)
+	c.Assert(err, IsNil)
+	blobs, err := l.Verify()
+	c.Assert(err, IsNil)
+	c.Assert(blobs[0].Validators, HasLen, 2)
+	c.Check(blobs[0].Validators[0].ValidationPublicKey.NodePublicKey(), Equals, "nHBt9fsb4849WmZiCds4r5TXyBeQjqnH5kzPtqgMAQMgi39YZRPa")
+	c.Check(blobs[0].Validators[1].Manifest, Equals, "")
+}
+
+// The fixtures are served lists in the vl.ripple.com layout. No published
+// list could be captured for them, so they were generated once by
+// NewValidatorList for the "publisher" key of signedManifest and are kept
+// as files to pin the wire format and signatures. Both list the published
+// manifest and the "second" validator under sequence 7, which expires at
+// 800000000. The version 2 list adds sequence 8 listing only the published
+// manifest, effective from 790000000 until 810000000.
+func (s *ValidatorListSuite) TestFixtures(c *C) {
+	publisher, _ := signedManifest(c, "publisher")
+	for _, test := range []struct {
+		file      string
+		version   uint32
+		sequences []uint32
+	}{
+		{"validator_list_v1.json", 1, []uint32{7}},
+		{"validator_list_v2.json", 2, []uint32{7, 8}},
+	} {
+		comment := Commentf(test.file)
+		b, err := ioutil.ReadFile("testdata/" + test.file)
+		c.Assert(err, IsNil, comment)
+		l, err := ParseValidatorList(b)
+		c.Assert(err, IsNil, comment)
+		c.Check(l.Version, Equals, test.version, comment)
+		c.Check(l.PublicKey, Equals, publisher.PublicKey, comment)
+		blobs, err := l.Verify()
+		c.Assert(err, IsNil, comment)
+		c.Assert(blobs, HasLen, len(test.sequences), comment)
+		for i, blob := range blobs {
+			c.Check(blob.Sequence, Equals, test.sequences[i], comment)
+			c.Check(blob.Validators[0].ValidationPublicKey.NodePublicKey(), Equals, "nHBt9fsb4849WmZiCds4r5TXyBeQjqnH5kzPtqgMAQMgi39YZRPa", comment)
+		}
+		c.Check(blobs[0].Effective, IsNil, comment)
+		c.Check(blobs[0].Expiration.Uint32(), Equals, uint32(800000000), comment)
+		c.Check(blobs[0].Validators, HasLen, 2, comment)
+
+		active, err := l.Active(*NewRippleTime(789999999))
+		c.Assert(err, IsNil, comment)
+		c.Check(active.Sequence, Equals, uint32(7), comment)
+		active, err = l.Active(*NewRippleTime(799999999))
+		c.Assert(err, IsNil, comment)
+		c.Check(active.Sequence, Equals, test.sequences[len(test.sequences)-1], comment)
+		_, err = l.Active(*NewRippleTime(810000000))
+		c.Check(err, ErrorMatches, "No validator list in effect at .*", comment)
+
+		// Any change to a served blob breaks its signature
+		blob := l.Blobs()[len(test.sequences)-1]
+		blob.Blob = blob.Blob[:len(blob.Blob)-4] + "AAAA"
+		if test.version == 1 {
+			l.SignedBlob = blob
+		} else {
+			l.BlobsV2[1] = blob
+		}
+		_, err = l.Verify()
+		c.Check(err, ErrorMatches, "Validator list blob [01]: Bad signature", comment)
+	}
+	b, err := ioutil.ReadFile("testdata/validator_list_v2.json")
+	c.Assert(err, IsNil)
+	l, err := ParseValidatorList(b)
+	c.Assert(err, IsNil)
+	blobs, err := l.Verify()
+	c.Assert(err, IsNil)
+	c.Check(blobs[1].Effective.Uint32(), Equals, uint32(790000000))
+	c.Check(blobs[1].Expiration.Uint32(), Equals, uint32(810000000))
+	c.Check(blobs[1].Validators, HasLen, 1)
+}
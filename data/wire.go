@@ -14,7 +14,8 @@ func (i *Issue) Marshal(w io.Writer) error {
 	if i.Currency.IsNative() {
 		return nil
 	}
-	return i.Issuer.Marshal(w)
+	// The issuer is not length prefixed as Account fields are
+	return write(w, i.Issuer.Bytes())
 }
 
 func (i *Issue) Unmarshal(r Reader) error {
@@ -0,0 +1,293 @@
+// Package keystore stores seeds encrypted under a passphrase.
+//
+// Each entry is sealed on its own with NaCl secretbox under a key derived
+// from the passphrase with scrypt, so entries can be moved between
+// keystores and may have different passphrases. The label, key type and
+// account of each entry are kept in cleartext so that a keystore can be
+// listed without unlocking it.
+package keystore
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/rubblelabs/ripple/data"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	version   = 1
+	kdfScrypt = "scrypt"
+	saltSize  = 32
+	nonceSize = 24
+	keySize   = 32
+)
+
+// Work factors for new entries. Existing entries keep those they were
+// sealed with.
+var (
+	scryptN = 1 << 18
+	scryptR = 8
+	scryptP = 1
+)
+
+// Limits on the work factors of entries read from elsewhere, so that a
+// hostile entry cannot make opening it use gigabytes of memory or hours of
+// time. scrypt uses 128*N*r bytes.
+const (
+	maxScryptN      = 1 << 20
+	maxScryptR      = 32
+	maxScryptP      = 16
+	maxScryptMemory = 1 << 30
+)
+
+// Crypto holds a sealed seed and how to derive the key which opens it
+type Crypto struct {
+	KDF        string              `json:"kdf"`
+	N          int                 `json:"n"`
+	R          int                 `json:"r"`
+	P          int                 `json:"p"`
+	Salt       data.VariableLength `json:"salt"`
+	Nonce      data.VariableLength `json:"nonce"`
+	Ciphertext data.VariableLength `json:"ciphertext"`
+}
+
+// Entry is a labeled seed. Everything but the seed is in cleartext.
+type Entry struct {
+	Label   string       `json:"label"`
+	KeyType data.KeyType `json:"key_type"`
+	Account data.Account `json:"account"`
+	Crypto  Crypto       `json:"crypto"`
+}
+
+type Keystore struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+func New() *Keystore {
+	return &Keystore{Version: version}
+}
+
+func Load(r io.Reader) (*Keystore, error) {
+	var k Keystore
+	if err := json.NewDecoder(r).Decode(&k); err != nil {
+		return nil, err
+	}
+	if k.Version != version {
+		return nil, fmt.Errorf("Unsupported keystore version: %d", k.Version)
+	}
+	for i := range k.Entries {
+		if err := k.Entries[i].Crypto.check(); err != nil {
+			return nil, fmt.Errorf("%s: %s", k.Entries[i].Label, err)
+		}
+	}
+	return &k, nil
+}
+
+// Open loads the keystore at path, or returns an empty one if there is no
+// file there yet.
+func Open(path string) (*Keystore, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+func (k *Keystore) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(k)
+}
+
+// WriteFile saves the keystore to path readable only by its owner
+func (k *Keystore) WriteFile(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := k.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// List returns the entries sorted by label
+func (k *Keystore) List() []Entry {
+	entries := append([]Entry(nil), k.Entries...)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Label < entries[j].Label
+	})
+	return entries
+}
+
+func (k *Keystore) find(label string) int {
+	for i := range k.Entries {
+		if k.Entries[i].Label == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// Add seals seed under passphrase and stores it with label
+func (k *Keystore) Add(label string, seed data.Seed, keyType data.KeyType, passphrase []byte) (*Entry, error) {
+	entry, err := seal(label, seed, keyType, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.Import(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Export returns the sealed entry with label, which can be imported into
+// another keystore
+func (k *Keystore) Export(label string) (*Entry, error) {
+	i := k.find(label)
+	if i < 0 {
+		return nil, fmt.Errorf("Unknown keystore label: %s", label)
+	}
+	entry := k.Entries[i]
+	return &entry, nil
+}
+
+// Import adds an entry exported from another keystore
+func (k *Keystore) Import(entry *Entry) error {
+	switch {
+	case len(entry.Label) == 0:
+		return fmt.Errorf("Missing keystore label")
+	case k.find(entry.Label) >= 0:
+		return fmt.Errorf("Duplicate keystore label: %s", entry.Label)
+	}
+	if err := entry.Crypto.check(); err != nil {
+		return err
+	}
+	k.Entries = append(k.Entries, *entry)
+	return nil
+}
+
+// Seed opens the entry with label using passphrase
+func (k *Keystore) Seed(label string, passphrase []byte) (*data.Seed, data.KeyType, error) {
+	entry, err := k.Export(label)
+	if err != nil {
+		return nil, 0, err
+	}
+	seed, err := entry.Open(passphrase)
+	if err != nil {
+		return nil, 0, err
+	}
+	return seed, entry.KeyType, nil
+}
+
+func seal(label string, seed data.Seed, keyType data.KeyType, passphrase []byte) (*Entry, error) {
+	if keyType != data.ECDSA && keyType != data.Ed25519 {
+		return nil, fmt.Errorf("Unknown key type: %d", keyType)
+	}
+	entry := &Entry{
+		Label:   label,
+		KeyType: keyType,
+		Account: accountId(seed, keyType),
+		Crypto: Crypto{
+			KDF:   kdfScrypt,
+			N:     scryptN,
+			R:     scryptR,
+			P:     scryptP,
+			Salt:  make(data.VariableLength, saltSize),
+			Nonce: make(data.VariableLength, nonceSize),
+		},
+	}
+	if _, err := io.ReadFull(rand.Reader, entry.Crypto.Salt); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, entry.Crypto.Nonce); err != nil {
+		return nil, err
+	}
+	key, err := entry.Crypto.key(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [nonceSize]byte
+	copy(nonce[:], entry.Crypto.Nonce)
+	entry.Crypto.Ciphertext = secretbox.Seal(nil, seed[:], &nonce, key)
+	return entry, nil
+}
+
+// Open returns the seed of the entry. The cleartext metadata is checked
+// against the seed.
+func (e *Entry) Open(passphrase []byte) (*data.Seed, error) {
+	if len(e.Crypto.Nonce) != nonceSize {
+		return nil, fmt.Errorf("Bad nonce length: %d", len(e.Crypto.Nonce))
+	}
+	key, err := e.Crypto.key(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [nonceSize]byte
+	copy(nonce[:], e.Crypto.Nonce)
+	b, ok := secretbox.Open(nil, e.Crypto.Ciphertext, &nonce, key)
+	if !ok {
+		return nil, fmt.Errorf("Wrong passphrase for %s", e.Label)
+	}
+	var seed data.Seed
+	if len(b) != len(seed) {
+		return nil, fmt.Errorf("Bad seed length: %d", len(b))
+	}
+	copy(seed[:], b)
+	if accountId(seed, e.KeyType) != e.Account {
+		return nil, fmt.Errorf("Account does not match seed for %s", e.Label)
+	}
+	return &seed, nil
+}
+
+// check returns an error unless the key derivation function is scrypt
+// with work factors within the limits
+func (c *Crypto) check() error {
+	switch {
+	case c.KDF != kdfScrypt:
+		return fmt.Errorf("Unknown key derivation function: %s", c.KDF)
+	case c.N < 2 || c.N > maxScryptN || c.N&(c.N-1) != 0:
+		return fmt.Errorf("Bad scrypt N: %d", c.N)
+	case c.R < 1 || c.R > maxScryptR:
+		return fmt.Errorf("Bad scrypt r: %d", c.R)
+	case c.P < 1 || c.P > maxScryptP:
+		return fmt.Errorf("Bad scrypt p: %d", c.P)
+	case 128*c.N*c.R > maxScryptMemory:
+		return fmt.Errorf("Too much memory for scrypt N=%d r=%d", c.N, c.R)
+	}
+	return nil
+}
+
+func (c *Crypto) key(passphrase []byte) (*[keySize]byte, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	b, err := scrypt.Key(passphrase, c.Salt, c.N, c.R, c.P, keySize)
+	if err != nil {
+		return nil, err
+	}
+	var key [keySize]byte
+	copy(key[:], b)
+	return &key, nil
+}
+
+func accountId(seed data.Seed, keyType data.KeyType) data.Account {
+	var sequence *uint32
+	// Ed25519 keys have no account families
+	if keyType == data.ECDSA {
+		sequence = new(uint32)
+	}
+	return seed.AccountId(keyType, sequence)
+}
@@ -0,0 +1,134 @@
+package keystore
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/rubblelabs/ripple/data"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type KeystoreSuite struct{}
+
+var _ = Suite(&KeystoreSuite{})
+
+func (s *KeystoreSuite) SetUpSuite(c *C) {
+	// Keep the tests fast
+	scryptN = 1 << 10
+}
+
+func seed(c *C, address string) data.Seed {
+	seed, _, err := data.NewSeedFromAddress(address)
+	c.Assert(err, IsNil)
+	return *seed
+}
+
+func (s *KeystoreSuite) TestAddAndOpen(c *C) {
+	ks := New()
+	entry, err := ks.Add("master", seed(c, "snoPBrXtMeMyMHUVTgbuqAfg1SUTb"), data.ECDSA, []byte("secret"))
+	c.Assert(err, IsNil)
+	c.Check(entry.Account.String(), Equals, "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh")
+	_, err = ks.Add("ed", seed(c, "sEdVQ4wvD1AaTG6JA54qt38TengAuiz"), data.Ed25519, []byte("other"))
+	c.Assert(err, IsNil)
+	_, err = ks.Add("master", seed(c, "snoPBrXtMeMyMHUVTgbuqAfg1SUTb"), data.ECDSA, []byte("secret"))
+	c.Check(err, ErrorMatches, "Duplicate keystore label: master")
+
+	var labels []string
+	for _, entry := range ks.List() {
+		labels = append(labels, entry.Label)
+	}
+	c.Check(labels, DeepEquals, []string{"ed", "master"})
+
+	opened, keyType, err := ks.Seed("ed", []byte("other"))
+	c.Assert(err, IsNil)
+	c.Check(*opened, Equals, seed(c, "sEdVQ4wvD1AaTG6JA54qt38TengAuiz"))
+	c.Check(keyType, Equals, data.Ed25519)
+
+	_, _, err = ks.Seed("ed", []byte("secret"))
+	c.Check(err, ErrorMatches, "Wrong passphrase for ed")
+	_, _, err = ks.Seed("missing", []byte("secret"))
+	c.Check(err, ErrorMatches, "Unknown keystore label: missing")
+}
+
+func (s *KeystoreSuite) TestSaveAndLoad(c *C) {
+	ks := New()
+	_, err := ks.Add("master", seed(c, "snoPBrXtMeMyMHUVTgbuqAfg1SUTb"), data.ECDSA, []byte("secret"))
+	c.Assert(err, IsNil)
+	path := filepath.Join(c.MkDir(), "keystore.json")
+	c.Assert(ks.WriteFile(path), IsNil)
+	loaded, err := Open(path)
+	c.Assert(err, IsNil)
+	c.Check(loaded, DeepEquals, ks)
+
+	var b bytes.Buffer
+	c.Assert(ks.Save(&b), IsNil)
+	c.Check(bytes.Contains(b.Bytes(), []byte(`"key_type": "ECDSA"`)), Equals, true)
+	c.Check(bytes.Contains(b.Bytes(), []byte(`"account": "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"`)), Equals, true)
+
+	empty, err := Open(filepath.Join(c.MkDir(), "missing.json"))
+	c.Assert(err, IsNil)
+	c.Check(empty.Entries, HasLen, 0)
+	_, err = Load(bytes.NewBufferString(`{"version":2}`))
+	c.Check(err, ErrorMatches, "Unsupported keystore version: 2")
+}
+
+func (s *KeystoreSuite) TestExportAndImport(c *C) {
+	from, to := New(), New()
+	_, err := from.Add("master", seed(c, "snoPBrXtMeMyMHUVTgbuqAfg1SUTb"), data.ECDSA, []byte("secret"))
+	c.Assert(err, IsNil)
+	entry, err := from.Export("master")
+	c.Assert(err, IsNil)
+	b, err := json.Marshal(entry)
+	c.Assert(err, IsNil)
+
+	var imported Entry
+	c.Assert(json.Unmarshal(b, &imported), IsNil)
+	imported.Label = "copy"
+	c.Assert(to.Import(&imported), IsNil)
+	opened, _, err := to.Seed("copy", []byte("secret"))
+	c.Assert(err, IsNil)
+	c.Check(*opened, Equals, seed(c, "snoPBrXtMeMyMHUVTgbuqAfg1SUTb"))
+
+	// The cleartext account is checked against the sealed seed
+	imported.Label = "tampered"
+	imported.Account = data.Account{}
+	c.Assert(to.Import(&imported), IsNil)
+	_, _, err = to.Seed("tampered", []byte("secret"))
+	c.Check(err, ErrorMatches, "Account does not match seed for tampered")
+}
+
+func (s *KeystoreSuite) TestWorkFactors(c *C) {
+	from := New()
+	_, err := from.Add("master", seed(c, "snoPBrXtMeMyMHUVTgbuqAfg1SUTb"), data.ECDSA, []byte("secret"))
+	c.Assert(err, IsNil)
+	for _, test := range []struct {
+		n, r, p int
+		message string
+	}{
+		{1 << 21, 8, 1, "Bad scrypt N: 2097152"},
+		{1000, 8, 1, "Bad scrypt N: 1000"},
+		{1, 8, 1, "Bad scrypt N: 1"},
+		{1 << 10, 0, 1, "Bad scrypt r: 0"},
+		{1 << 10, 33, 1, "Bad scrypt r: 33"},
+		{1 << 10, 8, 0, "Bad scrypt p: 0"},
+		{1 << 10, 8, 17, "Bad scrypt p: 17"},
+		{1 << 20, 16, 1, "Too much memory for scrypt N=1048576 r=16"},
+	} {
+		entry, err := from.Export("master")
+		c.Assert(err, IsNil)
+		entry.Crypto.N, entry.Crypto.R, entry.Crypto.P = test.n, test.r, test.p
+		c.Check(New().Import(entry), ErrorMatches, test.message)
+		_, err = entry.Open([]byte("secret"))
+		c.Check(err, ErrorMatches, test.message)
+
+		ks := &Keystore{Version: version, Entries: []Entry{*entry}}
+		var b bytes.Buffer
+		c.Assert(ks.Save(&b), IsNil)
+		_, err = Load(&b)
+		c.Check(err, ErrorMatches, "master: "+test.message)
+	}
+}
@@ -133,6 +133,10 @@ func newTxBundle(v data.Transaction, insert string, flag Flag) (*bundle, error)
 		format += "%-60s %d %d"
 		values = append(values, tx.LimitAmount, tx.QualityIn, tx.QualityOut)
 	}
+	if len(base.Memos) > 0 {
+		format += " %s"
+		values = append(values, base.Memos.Readable())
+	}
 	return &bundle{
 		color:  txStyle,
 		format: format,
@@ -44,9 +44,9 @@ func main() {
 
 	remote, err := websockets.NewRemote(*host)
 	checkErr(err)
-	gets, err := data.NewAsset(os.Args[1])
+	gets, err := data.NewIssue(os.Args[1])
 	checkErr(err)
-	pays, err := data.NewAsset(os.Args[2])
+	pays, err := data.NewIssue(os.Args[2])
 	checkErr(err)
 	var zeroAccount data.Account
 	result, err := remote.BookOffers(zeroAccount, "closed", *pays, *gets)
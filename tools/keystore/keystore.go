@@ -0,0 +1,85 @@
+// Tool to manage a keystore of encrypted seeds.
+//
+// The passphrase is read from $RIPPLE_KEYSTORE_PASSPHRASE and seeds to add
+// are read from stdin so that neither appear in the process arguments.
+//
+//	keystore list
+//	keystore add <label> < seed
+//	keystore export <label> > entry.json
+//	keystore import < entry.json
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/keystore"
+)
+
+var path = flag.String("keystore", "keystore.json", "keystore file")
+
+func checkErr(err error) {
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] list|add <label>|export <label>|import\n", os.Args[0])
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func passphrase() []byte {
+	p := os.Getenv("RIPPLE_KEYSTORE_PASSPHRASE")
+	if p == "" {
+		log.Fatalln("RIPPLE_KEYSTORE_PASSPHRASE is not set")
+	}
+	return []byte(p)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+	}
+	ks, err := keystore.Open(*path)
+	checkErr(err)
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		for _, entry := range ks.List() {
+			fmt.Printf("%-20s %-8s %s\n", entry.Label, entry.KeyType, entry.Account)
+		}
+	case args[0] == "add" && len(args) == 2:
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if line == "" {
+			checkErr(err)
+		}
+		seed, err := data.NewTypedSeed(strings.TrimSpace(line))
+		checkErr(err)
+		entry, err := ks.Add(args[1], seed.Seed, seed.KeyType, passphrase())
+		checkErr(err)
+		checkErr(ks.WriteFile(*path))
+		fmt.Println(entry.Account)
+	case args[0] == "export" && len(args) == 2:
+		entry, err := ks.Export(args[1])
+		checkErr(err)
+		checkErr(json.NewEncoder(os.Stdout).Encode(entry))
+	case args[0] == "import" && len(args) == 1:
+		var entry keystore.Entry
+		checkErr(json.NewDecoder(os.Stdin).Decode(&entry))
+		checkErr(ks.Import(&entry))
+		checkErr(ks.WriteFile(*path))
+		fmt.Println(entry.Label)
+	default:
+		usage()
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"os"
 
 	"github.com/rubblelabs/ripple/config"
+	"github.com/rubblelabs/ripple/keystore"
 )
 
 var (
-	host = flag.String("host", "wss://s2.ripple.com:443", "websockets host")
+	host  = flag.String("host", "wss://s2.ripple.com:443", "websockets host")
+	store = flag.String("keystore", "", "keystore for actions with a label, unlocked with $RIPPLE_KEYSTORE_PASSPHRASE")
 )
 
 func checkErr(err error) {
@@ -22,6 +24,11 @@ func main() {
 	flag.Parse()
 	actions, err := config.Parse(os.Stdin)
 	checkErr(err)
+	if *store != "" {
+		ks, err := keystore.Open(*store)
+		checkErr(err)
+		checkErr(actions.Unlock(ks, []byte(os.Getenv("RIPPLE_KEYSTORE_PASSPHRASE"))))
+	}
 	checkErr(actions.Prepare())
 	checkErr(actions.Submit(*host))
 	log.Printf("Submitted %d transactions", actions.Count())
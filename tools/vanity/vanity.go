@@ -40,6 +40,7 @@ type Trial struct {
 func search(c chan *Trial, target *regexp.Regexp) {
 	sequence := uint32(0)
 	batch := make([]byte, 1024*4)
+	address := make([]byte, 0, 35)
 	for {
 		_, err := rand.Read(batch)
 		checkErr(err)
@@ -61,7 +62,9 @@ func search(c chan *Trial, target *regexp.Regexp) {
 			}
 			checkErr(err)
 			atomic.AddUint64(&count, 1)
-			if target.MatchString(trial.Id.String()) {
+			address, err = crypto.AppendRippleHash(address[:0], crypto.RIPPLE_ACCOUNT_ID, trial.Id.Payload())
+			checkErr(err)
+			if target.Match(address) {
 				c <- trial
 			}
 		}
@@ -95,7 +98,11 @@ func main() {
 			log.Printf("Tested: %d seeds at %.2f/sec", num, float64(num)/time.Since(start).Seconds())
 			return
 		case trial := <-c:
-			s, err := crypto.NewFamilySeed(trial.Seed)
+			newSeed := crypto.NewFamilySeed
+			if *ed25519key {
+				newSeed = crypto.NewEd25519FamilySeed
+			}
+			s, err := newSeed(trial.Seed)
 			checkErr(err)
 			log.Println(s, trial.Id)
 		}
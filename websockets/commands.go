@@ -265,8 +265,8 @@ type BookOffersCommand struct {
 	*Command
 	LedgerIndex interface{}  `json:"ledger_index,omitempty"`
 	Taker       data.Account `json:"taker"`
-	TakerPays   data.Asset   `json:"taker_pays"`
-	TakerGets   data.Asset   `json:"taker_gets"`
+	TakerPays   data.Issue   `json:"taker_pays"`
+	TakerGets   data.Issue   `json:"taker_gets"`
 	Limit       uint32       `json:"limit"`
 	Result      *BookOffersResult
 }
@@ -300,3 +300,35 @@ type FeeResult struct {
 	MaxQueueSize uint32 `json:"max_queue_size,string"`
 	Status       string `json:"status"`
 }
+
+type ChannelAuthorizeCommand struct {
+	*Command
+	Channel data.Hash256            `json:"channel_id"`
+	Amount  data.Value              `json:"amount"`
+	Seed    data.Seed               `json:"seed"`
+	KeyType string                  `json:"key_type"`
+	Result  *ChannelAuthorizeResult `json:"result,omitempty"`
+}
+
+type ChannelAuthorizeResult struct {
+	Signature data.VariableLength `json:"signature"`
+}
+
+type ChannelVerifyCommand struct {
+	*Command
+	Channel   data.Hash256         `json:"channel_id"`
+	Amount    data.Value           `json:"amount"`
+	PublicKey data.PublicKey       `json:"public_key"`
+	Signature data.VariableLength  `json:"signature"`
+	Result    *ChannelVerifyResult `json:"result,omitempty"`
+}
+
+type ChannelVerifyResult struct {
+	SignatureVerified bool `json:"signature_verified"`
+}
+
+// keyTypes are the names rippled uses
+var keyTypes = map[data.KeyType]string{
+	data.ECDSA:   "secp256k1",
+	data.Ed25519: "ed25519",
+}
@@ -160,3 +160,38 @@ func (s *MessagesSuite) TestAccountInfoResponse(c *C) {
 	c.Assert(*msg.Result.AccountData.Sequence, Equals, uint32(546))
 	c.Assert(msg.Result.AccountData.Balance.String(), Equals, "10321199.422233")
 }
+
+func (s *MessagesSuite) TestChannelAuthorizeRequest(c *C) {
+	channel, err := data.NewHash256("5DB01B7FFED6B67E6B0414DED11E051D2EE2B7619CE0EAA6286D67A3A4D5BDB3")
+	c.Assert(err, IsNil)
+	amount, err := data.NewNativeValue(1000000)
+	c.Assert(err, IsNil)
+	seed, _, err := data.NewSeedFromAddress("snoPBrXtMeMyMHUVTgbuqAfg1SUTb")
+	c.Assert(err, IsNil)
+	cmd := &ChannelAuthorizeCommand{
+		Command: &Command{Id: 1, Name: "channel_authorize"},
+		Channel: *channel,
+		Amount:  *amount,
+		Seed:    *seed,
+		KeyType: keyTypes[data.ECDSA],
+	}
+	b, err := json.Marshal(cmd)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `{"id":1,"command":"channel_authorize","channel_id":"5DB01B7FFED6B67E6B0414DED11E051D2EE2B7619CE0EAA6286D67A3A4D5BDB3","amount":"1000000","seed":"snoPBrXtMeMyMHUVTgbuqAfg1SUTb","key_type":"secp256k1"}`)
+}
+
+// The response is the example of the XRPL documentation, signed by the key
+// aB44YfzW24VDEJQ2UuLPV2PvqcPCSoLnL7y5M1EzhdW4LnK5xMS3.
+func (s *MessagesSuite) TestChannelAuthorize(c *C) {
+	msg := &ChannelAuthorizeCommand{}
+	readResponseFile(c, msg, "testdata/channel_authorize.json")
+	c.Assert(msg.Status, Equals, "success")
+	c.Assert(msg.Result.Signature.String(), Equals, "304402204EF0AFB78AC23ED1C472E74F4299C0C21F1B21D07EFC0A3838A420F76D783A400220154FB11B6F54320666E4C36CA7F686C16A3A0456800BBC43746F34AF50290064")
+}
+
+func (s *MessagesSuite) TestChannelVerify(c *C) {
+	msg := &ChannelVerifyCommand{}
+	readResponseFile(c, msg, "testdata/channel_verify.json")
+	c.Assert(msg.Status, Equals, "success")
+	c.Assert(msg.Result.SignatureVerified, Equals, true)
+}
@@ -0,0 +1,395 @@
+package websockets
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+// OrderBook holds both sides of the market between two assets in memory.
+// It is seeded from a snapshot of the offers and then kept up to date by
+// passing it the messages from the Remote's Incoming channel after calling
+// SubscribeOrderBooks with its Subscription. Prices are in Counter per
+// Base, with XRP at face value.
+type OrderBook struct {
+	Base    data.Issue
+	Counter data.Issue
+	// Asks are the offers selling Base, lowest price first
+	Asks []data.OrderBookOffer
+	// Bids are the offers buying Base, highest price first
+	Bids []data.OrderBookOffer
+	// SnapshotLedger is the ledger the offers were loaded from. Only the
+	// transactions of later ledgers are applied.
+	SnapshotLedger uint32
+	// LedgerSequence is the last closed ledger seen by the book
+	LedgerSequence uint32
+	remote         *Remote
+	// fetch returns a snapshot in place of the remote
+	fetch func() (uint32, []data.OrderBookOffer, []data.OrderBookOffer, error)
+	// resync delivers the snapshot requested after a missed ledger
+	resync chan *orderBookSnapshot
+	// resyncLedger is the ledger which showed the gap
+	resyncLedger uint32
+	// pending holds the transactions received while resync is outstanding
+	pending []*TransactionStreamMsg
+}
+
+type orderBookSnapshot struct {
+	ledger     uint32
+	asks, bids []data.OrderBookOffer
+	err        error
+}
+
+// NewOrderBook returns an OrderBook seeded using BookOffers. remote is used
+// again to resynchronise the book whenever a ledger is missed.
+func NewOrderBook(remote *Remote, base, counter data.Issue) (*OrderBook, error) {
+	b := &OrderBook{
+		Base:    base,
+		Counter: counter,
+		remote:  remote,
+	}
+	return b, b.Sync()
+}
+
+// Subscription returns the subscription which streams the updates to b
+func (b *OrderBook) Subscription() OrderBookSubscription {
+	return OrderBookSubscription{
+		TakerGets: b.Base,
+		TakerPays: b.Counter,
+		Snapshot:  true,
+		Both:      true,
+	}
+}
+
+// Sync replaces the offers of b with those of the last validated ledger.
+// It waits for the responses of the Remote, so it must not be called from
+// the goroutine which reads the Remote's Incoming channel once the book is
+// subscribed.
+func (b *OrderBook) Sync() error {
+	snapshot := b.snapshot()
+	if snapshot.err != nil {
+		return snapshot.err
+	}
+	return b.Load(snapshot.ledger, snapshot.asks, snapshot.bids)
+}
+
+func (b *OrderBook) snapshot() *orderBookSnapshot {
+	if b.fetch != nil {
+		ledger, asks, bids, err := b.fetch()
+		return &orderBookSnapshot{ledger, asks, bids, err}
+	}
+	if b.remote == nil {
+		return &orderBookSnapshot{err: fmt.Errorf("Cannot sync order book %s/%s without a remote", b.Base, b.Counter)}
+	}
+	var zeroAccount data.Account
+	asks, err := b.remote.BookOffers(zeroAccount, "validated", b.Counter, b.Base)
+	if err != nil {
+		return &orderBookSnapshot{err: err}
+	}
+	bids, err := b.remote.BookOffers(zeroAccount, asks.LedgerSequence, b.Base, b.Counter)
+	if err != nil {
+		return &orderBookSnapshot{err: err}
+	}
+	return &orderBookSnapshot{ledger: asks.LedgerSequence, asks: asks.Offers, bids: bids.Offers}
+}
+
+// Load replaces the offers of b with a snapshot taken at ledger, such as
+// the results of BookOffers or of SubscribeOrderBooks with Snapshot and Both
+// set.
+func (b *OrderBook) Load(ledger uint32, asks, bids []data.OrderBookOffer) error {
+	b.Asks, b.Bids = nil, nil
+	for _, side := range [][]data.OrderBookOffer{asks, bids} {
+		for i := range side {
+			if err := b.insert(&side[i].Offer, side[i]); err != nil {
+				return err
+			}
+		}
+	}
+	b.SnapshotLedger = ledger
+	if ledger > b.LedgerSequence {
+		b.LedgerSequence = ledger
+	}
+	return nil
+}
+
+// Update applies a message received from the Remote. Transactions are
+// applied when their ledger is later than SnapshotLedger.
+//
+// A gap in the sequence of the ledger stream starts a resynchronisation on
+// another goroutine, so that Update never waits for the Remote. Until the
+// new snapshot arrives the book keeps its stale offers and the transactions
+// are held back, to be applied on top of the snapshot by a later call to
+// Update or WaitSync. Gaps can only be seen with the ledger stream, which
+// SubscribeOrderBooks includes.
+func (b *OrderBook) Update(msg interface{}) error {
+	if err := b.finishSync(false); err != nil {
+		return err
+	}
+	switch msg := msg.(type) {
+	case *LedgerStreamMsg:
+		if b.LedgerSequence != 0 && msg.LedgerSequence > b.LedgerSequence+1 {
+			b.startSync(msg.LedgerSequence)
+		}
+		if msg.LedgerSequence > b.LedgerSequence {
+			b.LedgerSequence = msg.LedgerSequence
+		}
+	case *TransactionStreamMsg:
+		switch {
+		case !msg.Validated:
+		case b.resync != nil:
+			b.pending = append(b.pending, msg)
+		case msg.LedgerSequence > b.SnapshotLedger:
+			return b.Apply(&msg.Transaction)
+		}
+	}
+	return nil
+}
+
+// Syncing reports whether a resynchronisation is outstanding
+func (b *OrderBook) Syncing() bool {
+	return b.resync != nil
+}
+
+// WaitSync waits for an outstanding resynchronisation and applies it
+func (b *OrderBook) WaitSync() error {
+	return b.finishSync(true)
+}
+
+func (b *OrderBook) startSync(ledger uint32) {
+	if b.resync != nil {
+		return
+	}
+	b.resync = make(chan *orderBookSnapshot, 1)
+	b.resyncLedger = ledger
+	go func(resync chan<- *orderBookSnapshot) {
+		resync <- b.snapshot()
+	}(b.resync)
+}
+
+func (b *OrderBook) finishSync(wait bool) error {
+	if b.resync == nil {
+		return nil
+	}
+	var snapshot *orderBookSnapshot
+	if wait {
+		snapshot = <-b.resync
+	} else {
+		select {
+		case snapshot = <-b.resync:
+		default:
+			return nil
+		}
+	}
+	pending := b.pending
+	b.resync, b.pending = nil, nil
+	if snapshot.err != nil {
+		// The next ledger shows the gap again and retries
+		b.LedgerSequence = b.SnapshotLedger
+		return snapshot.err
+	}
+	if snapshot.ledger+1 < b.resyncLedger {
+		b.LedgerSequence = b.SnapshotLedger
+		return fmt.Errorf("Order book %s/%s synced to ledger %d before ledger %d", b.Base, b.Counter, snapshot.ledger, b.resyncLedger)
+	}
+	if err := b.Load(snapshot.ledger, snapshot.asks, snapshot.bids); err != nil {
+		return err
+	}
+	for _, msg := range pending {
+		if msg.LedgerSequence > b.SnapshotLedger {
+			if err := b.Apply(&msg.Transaction); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Apply updates the offers of b with the nodes affected by txm
+func (b *OrderBook) Apply(txm *data.TransactionWithMetaData) error {
+	for i := range txm.MetaData.AffectedNodes {
+		_, final, _, state := txm.MetaData.AffectedNodes[i].AffectedNode()
+		offer, ok := final.(*data.Offer)
+		if !ok || offer.Account == nil || offer.Sequence == nil || offer.TakerPays == nil || offer.TakerGets == nil {
+			continue
+		}
+		side := b.side(offer)
+		if side == nil {
+			continue
+		}
+		j := find(*side, offer)
+		switch {
+		case state == data.Deleted && j >= 0:
+			*side = append((*side)[:j], (*side)[j+1:]...)
+		case state == data.Deleted:
+		case j >= 0:
+			// Funding is only known from the snapshot
+			existing := &(*side)[j]
+			existing.TakerPays, existing.TakerGets = offer.TakerPays, offer.TakerGets
+			existing.TakerPaysFunded, existing.TakerGetsFunded = nil, nil
+		default:
+			if err := b.insert(offer, data.OrderBookOffer{Offer: *offer}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *OrderBook) side(offer *data.Offer) *[]data.OrderBookOffer {
+	switch {
+	case b.Base.Matches(offer.TakerGets) && b.Counter.Matches(offer.TakerPays):
+		return &b.Asks
+	case b.Counter.Matches(offer.TakerGets) && b.Base.Matches(offer.TakerPays):
+		return &b.Bids
+	default:
+		return nil
+	}
+}
+
+// insert adds o after the offers of the same or better quality
+func (b *OrderBook) insert(offer *data.Offer, o data.OrderBookOffer) error {
+	side := b.side(offer)
+	if side == nil {
+		return fmt.Errorf("Offer not in order book %s/%s: %s for %s", b.Base, b.Counter, offer.TakerGets, offer.TakerPays)
+	}
+	q, err := quality(&o.Offer)
+	if err != nil {
+		return err
+	}
+	if o.Quality.IsZero() {
+		o.Quality.Value = *q.Value()
+	}
+	i := sort.Search(len(*side), func(i int) bool {
+		other, err := quality(&(*side)[i].Offer)
+		return err == nil && other > q
+	})
+	*side = append(*side, data.OrderBookOffer{})
+	copy((*side)[i+1:], (*side)[i:])
+	(*side)[i] = o
+	return nil
+}
+
+func quality(offer *data.Offer) (data.ExchangeRate, error) {
+	if offer.BookDirectory != nil {
+		return data.GetQuality(*offer.BookDirectory), nil
+	}
+	return data.NewExchangeRate(offer.TakerPays, offer.TakerGets)
+}
+
+func find(offers []data.OrderBookOffer, offer *data.Offer) int {
+	for i := range offers {
+		if *offers[i].Account == *offer.Account && *offers[i].Sequence == *offer.Sequence {
+			return i
+		}
+	}
+	return -1
+}
+
+// AskPrice returns the price of an ask
+func AskPrice(offer *data.OrderBookOffer) (*data.Value, error) {
+	return offer.TakerPays.Value.Ratio(*offer.TakerGets.Value)
+}
+
+// BidPrice returns the price of a bid
+func BidPrice(offer *data.OrderBookOffer) (*data.Value, error) {
+	return offer.TakerGets.Value.Ratio(*offer.TakerPays.Value)
+}
+
+func (b *OrderBook) best() (*data.Value, *data.Value, error) {
+	if len(b.Asks) == 0 || len(b.Bids) == 0 {
+		return nil, nil, fmt.Errorf("Order book %s/%s is one sided", b.Base, b.Counter)
+	}
+	ask, err := AskPrice(&b.Asks[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	bid, err := BidPrice(&b.Bids[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return ask, bid, nil
+}
+
+// Spread returns the best ask price less the best bid price
+func (b *OrderBook) Spread() (*data.Value, error) {
+	ask, bid, err := b.best()
+	if err != nil {
+		return nil, err
+	}
+	return ask.Subtract(*bid)
+}
+
+// Mid returns the price half way between the best ask and the best bid
+func (b *OrderBook) Mid() (*data.Value, error) {
+	ask, bid, err := b.best()
+	if err != nil {
+		return nil, err
+	}
+	sum, err := ask.Add(*bid)
+	if err != nil {
+		return nil, err
+	}
+	two, err := data.NewNonNativeValue(2, 0)
+	if err != nil {
+		return nil, err
+	}
+	return sum.Divide(*two)
+}
+
+// AskDepth returns the amount of Base offered at price or lower. The funded
+// amounts of the snapshot are used when they are known.
+func (b *OrderBook) AskDepth(price data.Value) (*data.Value, error) {
+	total := b.zero()
+	for i := range b.Asks {
+		ask := &b.Asks[i]
+		p, err := AskPrice(ask)
+		if err != nil {
+			return nil, err
+		}
+		if price.Less(*p) {
+			break
+		}
+		amount := ask.TakerGets
+		if ask.TakerGetsFunded != nil {
+			amount = ask.TakerGetsFunded
+		}
+		if total, err = total.Add(*amount.Value); err != nil {
+			return nil, err
+		}
+	}
+	return total, nil
+}
+
+// BidDepth returns the amount of Base wanted at price or higher. The funded
+// amounts of the snapshot are used when they are known.
+func (b *OrderBook) BidDepth(price data.Value) (*data.Value, error) {
+	total := b.zero()
+	for i := range b.Bids {
+		bid := &b.Bids[i]
+		p, err := BidPrice(bid)
+		if err != nil {
+			return nil, err
+		}
+		if p.Less(price) {
+			break
+		}
+		amount := bid.TakerPays
+		if bid.TakerPaysFunded != nil {
+			amount = bid.TakerPaysFunded
+		}
+		if total, err = total.Add(*amount.Value); err != nil {
+			return nil, err
+		}
+	}
+	return total, nil
+}
+
+func (b *OrderBook) zero() *data.Value {
+	if b.Base.IsNative() {
+		v, _ := data.NewNativeValue(0)
+		return v
+	}
+	v, _ := data.NewNonNativeValue(0, 0)
+	return v
+}
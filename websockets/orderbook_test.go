@@ -0,0 +1,198 @@
+package websockets
+
+import (
+	"github.com/rubblelabs/ripple/data"
+	. "gopkg.in/check.v1"
+)
+
+type OrderBookSuite struct{}
+
+var _ = Suite(&OrderBookSuite{})
+
+const orderBookCNY = "CNY/razqQKzJRdB4UxFPWf5NEpEG3WMkmwgcXA"
+
+func orderBookOffer(c *C, account string, sequence uint32, gets, pays string) data.OrderBookOffer {
+	owner, err := data.NewAccountFromAddress(account)
+	c.Assert(err, IsNil)
+	takerGets, err := data.NewAmount(gets)
+	c.Assert(err, IsNil)
+	takerPays, err := data.NewAmount(pays)
+	c.Assert(err, IsNil)
+	return data.OrderBookOffer{
+		Offer: data.Offer{
+			Account:   owner,
+			Sequence:  &sequence,
+			TakerGets: takerGets,
+			TakerPays: takerPays,
+		},
+	}
+}
+
+func loadOrderBook(c *C, ledger uint32) *OrderBook {
+	counter, err := data.NewIssue(orderBookCNY)
+	c.Assert(err, IsNil)
+	b := &OrderBook{
+		Base:    data.Issue{},
+		Counter: *counter,
+	}
+	asks := []data.OrderBookOffer{
+		orderBookOffer(c, "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", 2, "2000/XRP", "62/"+orderBookCNY),
+		orderBookOffer(c, "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", 1, "1000/XRP", "30/"+orderBookCNY),
+	}
+	bids := []data.OrderBookOffer{
+		orderBookOffer(c, "rJMNfiJTwXHcMdB4SpxMgL3mvV4xUVHDnd", 1, "27/"+orderBookCNY, "1000/XRP"),
+	}
+	c.Assert(b.Load(ledger, asks, bids), IsNil)
+	return b
+}
+
+func (s *OrderBookSuite) TestLoad(c *C) {
+	b := loadOrderBook(c, 6959248)
+	c.Assert(b.Asks, HasLen, 2)
+	c.Check(*b.Asks[0].Sequence, Equals, uint32(1))
+	mid, err := b.Mid()
+	c.Assert(err, IsNil)
+	c.Check(mid.String(), Equals, "0.0285")
+	spread, err := b.Spread()
+	c.Assert(err, IsNil)
+	c.Check(spread.String(), Equals, "0.003")
+	price, err := data.NewValue("0.0305", false)
+	c.Assert(err, IsNil)
+	depth, err := b.AskDepth(*price)
+	c.Assert(err, IsNil)
+	c.Check(depth.String(), Equals, "1000")
+	depth, err = b.BidDepth(*price)
+	c.Assert(err, IsNil)
+	c.Check(depth.String(), Equals, "0")
+}
+
+func (s *OrderBookSuite) TestUpdate(c *C) {
+	msg := streamMessageFactory["transaction"]().(*TransactionStreamMsg)
+	readResponseFile(c, msg, "testdata/transactions_stream.json")
+
+	b := loadOrderBook(c, 6959248)
+	c.Assert(b.Update(msg), IsNil)
+	c.Assert(b.Asks, HasLen, 3)
+	c.Check(*b.Asks[0].Sequence, Equals, uint32(753273))
+	c.Check(b.Bids, HasLen, 1)
+
+	// Transactions already in the snapshot are skipped
+	b = loadOrderBook(c, 6959249)
+	c.Assert(b.Update(msg), IsNil)
+	c.Check(b.Asks, HasLen, 2)
+
+	c.Assert(b.Update(&LedgerStreamMsg{LedgerSequence: 6959250}), IsNil)
+	c.Check(b.LedgerSequence, Equals, uint32(6959250))
+	c.Check(b.SnapshotLedger, Equals, uint32(6959249))
+}
+
+func (s *OrderBookSuite) TestLedgerThenTransaction(c *C) {
+	msg := streamMessageFactory["transaction"]().(*TransactionStreamMsg)
+	readResponseFile(c, msg, "testdata/transactions_stream.json")
+
+	// rippled sends ledgerClosed before the transactions of the ledger
+	b := loadOrderBook(c, 6959248)
+	c.Assert(b.Update(&LedgerStreamMsg{LedgerSequence: 6959249}), IsNil)
+	c.Check(b.Syncing(), Equals, false)
+	c.Assert(b.Update(msg), IsNil)
+	c.Assert(b.Asks, HasLen, 3)
+	c.Check(*b.Asks[0].Sequence, Equals, uint32(753273))
+	c.Check(b.LedgerSequence, Equals, uint32(6959249))
+	c.Check(b.SnapshotLedger, Equals, uint32(6959248))
+}
+
+func (s *OrderBookSuite) TestTransactionsWithoutGap(c *C) {
+	msg := streamMessageFactory["transaction"]().(*TransactionStreamMsg)
+	readResponseFile(c, msg, "testdata/transactions_stream.json")
+
+	// Ledgers without transactions for the book are not gaps
+	b := loadOrderBook(c, 6959240)
+	c.Assert(b.Update(msg), IsNil)
+	c.Check(b.Syncing(), Equals, false)
+	c.Check(b.Asks, HasLen, 3)
+}
+
+func (s *OrderBookSuite) TestGap(c *C) {
+	msg := streamMessageFactory["transaction"]().(*TransactionStreamMsg)
+	readResponseFile(c, msg, "testdata/transactions_stream.json")
+
+	// Without a remote the resync fails and is retried on the next ledger
+	b := loadOrderBook(c, 6959240)
+	c.Assert(b.Update(&LedgerStreamMsg{LedgerSequence: 6959245}), IsNil)
+	c.Check(b.Syncing(), Equals, true)
+	c.Assert(b.Update(msg), IsNil)
+	c.Check(b.Asks, HasLen, 2)
+	c.Check(b.WaitSync(), ErrorMatches, "Cannot sync order book .*")
+	c.Check(b.Syncing(), Equals, false)
+	c.Check(b.LedgerSequence, Equals, uint32(6959240))
+	c.Assert(b.Update(&LedgerStreamMsg{LedgerSequence: 6959246}), IsNil)
+	c.Check(b.Syncing(), Equals, true)
+	c.Check(b.WaitSync(), NotNil)
+
+	c.Assert(b.Update(&LedgerStreamMsg{LedgerSequence: 6959241}), IsNil)
+	c.Check(b.Syncing(), Equals, false)
+	c.Check(b.LedgerSequence, Equals, uint32(6959241))
+}
+
+func (s *OrderBookSuite) TestGapThenApply(c *C) {
+	msg := streamMessageFactory["transaction"]().(*TransactionStreamMsg)
+	readResponseFile(c, msg, "testdata/transactions_stream.json")
+	gap := &LedgerStreamMsg{LedgerSequence: 6959249}
+
+	// The snapshot is from the ledger before the message
+	b := loadOrderBook(c, 6959240)
+	snapshot := loadOrderBook(c, 6959248)
+	release := make(chan struct{})
+	b.fetch = func() (uint32, []data.OrderBookOffer, []data.OrderBookOffer, error) {
+		<-release
+		return snapshot.SnapshotLedger, snapshot.Asks, snapshot.Bids, nil
+	}
+	c.Assert(b.Update(gap), IsNil)
+	// The stream is not blocked while the snapshot is fetched
+	c.Assert(b.Update(msg), IsNil)
+	c.Check(b.Syncing(), Equals, true)
+	c.Check(b.Asks, HasLen, 2)
+	close(release)
+	c.Assert(b.WaitSync(), IsNil)
+	c.Check(b.SnapshotLedger, Equals, uint32(6959248))
+	c.Check(b.LedgerSequence, Equals, uint32(6959249))
+	c.Assert(b.Asks, HasLen, 3)
+	c.Check(*b.Asks[0].Sequence, Equals, uint32(753273))
+
+	// The snapshot already includes the message
+	b = loadOrderBook(c, 6959240)
+	snapshot = loadOrderBook(c, 6959249)
+	b.fetch = func() (uint32, []data.OrderBookOffer, []data.OrderBookOffer, error) {
+		return snapshot.SnapshotLedger, snapshot.Asks, snapshot.Bids, nil
+	}
+	c.Assert(b.Update(gap), IsNil)
+	c.Assert(b.Update(msg), IsNil)
+	c.Assert(b.WaitSync(), IsNil)
+	c.Check(b.Asks, HasLen, 2)
+
+	// The snapshot is still behind the gap
+	b = loadOrderBook(c, 6959240)
+	snapshot = loadOrderBook(c, 6959247)
+	b.fetch = func() (uint32, []data.OrderBookOffer, []data.OrderBookOffer, error) {
+		return snapshot.SnapshotLedger, snapshot.Asks, snapshot.Bids, nil
+	}
+	c.Assert(b.Update(gap), IsNil)
+	c.Check(b.WaitSync(), ErrorMatches, "Order book .* synced to ledger 6959247 before ledger 6959249")
+	c.Check(b.SnapshotLedger, Equals, uint32(6959240))
+}
+
+func (s *OrderBookSuite) TestDelete(c *C) {
+	b := loadOrderBook(c, 1)
+	offer := b.Asks[0].Offer
+	offer.LedgerEntryType = data.OFFER
+	txm := &data.TransactionWithMetaData{}
+	txm.MetaData.AffectedNodes = []data.NodeEffect{{
+		DeletedNode: &data.AffectedNode{
+			LedgerEntryType: data.OFFER,
+			FinalFields:     &offer,
+		},
+	}}
+	c.Assert(b.Apply(txm), IsNil)
+	c.Assert(b.Asks, HasLen, 1)
+	c.Check(*b.Asks[0].Sequence, Equals, uint32(2))
+}
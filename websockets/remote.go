@@ -447,7 +447,7 @@ func (r *Remote) AccountOffers(account data.Account, ledgerIndex interface{}) (*
 	}
 }
 
-func (r *Remote) BookOffers(taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*BookOffersResult, error) {
+func (r *Remote) BookOffers(taker data.Account, ledgerIndex interface{}, pays, gets data.Issue) (*BookOffersResult, error) {
 	cmd := &BookOffersCommand{
 		Command:     newCommand("book_offers"),
 		LedgerIndex: ledgerIndex,
@@ -500,8 +500,8 @@ func (r *Remote) Subscribe(ledger, transactions, transactionsProposed, server bo
 }
 
 type OrderBookSubscription struct {
-	TakerGets data.Asset `json:"taker_gets"`
-	TakerPays data.Asset `json:"taker_pays"`
+	TakerGets data.Issue `json:"taker_gets"`
+	TakerPays data.Issue `json:"taker_pays"`
 	Snapshot  bool       `json:"snapshot"`
 	Both      bool       `json:"both"`
 }
@@ -532,6 +532,43 @@ func (r *Remote) Fee() (*FeeResult, error) {
 	return cmd.Result, nil
 }
 
+// Synchronously requests the signature of a payment channel claim for
+// amount drops. The seed is sent to the server, so use data.SignClaim
+// unless the server is trusted.
+func (r *Remote) ChannelAuthorize(channel data.Hash256, amount data.Value, seed data.Seed, keyType data.KeyType) (*ChannelAuthorizeResult, error) {
+	cmd := &ChannelAuthorizeCommand{
+		Command: newCommand("channel_authorize"),
+		Channel: channel,
+		Amount:  amount,
+		Seed:    seed,
+		KeyType: keyTypes[keyType],
+	}
+	r.outgoing <- cmd
+	<-cmd.Ready
+	if cmd.CommandError != nil {
+		return nil, cmd.CommandError
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests verification of a payment channel claim for
+// amount drops
+func (r *Remote) ChannelVerify(channel data.Hash256, amount data.Value, publicKey data.PublicKey, signature data.VariableLength) (*ChannelVerifyResult, error) {
+	cmd := &ChannelVerifyCommand{
+		Command:   newCommand("channel_verify"),
+		Channel:   channel,
+		Amount:    amount,
+		PublicKey: publicKey,
+		Signature: signature,
+	}
+	r.outgoing <- cmd
+	<-cmd.Ready
+	if cmd.CommandError != nil {
+		return nil, cmd.CommandError
+	}
+	return cmd.Result, nil
+}
+
 // readPump reads from the websocket and sends to inbound channel.
 // Expects to receive PONGs at specified interval, or logs an error and returns.
 func (r *Remote) readPump(inbound chan<- []byte) {